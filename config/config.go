@@ -10,3 +10,129 @@ func Load() {
 	configFile := "config.toml"
 	Data, _ = toml.LoadFile(configFile)
 }
+
+// GetString returns the string stored at key, or fallback when it is not set.
+func GetString(key string, fallback string) string {
+	if Data == nil {
+		return fallback
+	}
+	if value, ok := Data.Get(key).(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetInt returns the integer stored at key, or fallback when it is not set.
+func GetInt(key string, fallback int) int {
+	if Data == nil {
+		return fallback
+	}
+	if value, ok := Data.Get(key).(int64); ok {
+		return int(value)
+	}
+	return fallback
+}
+
+// GetStrings returns the list of strings stored at key, or nil when it is not set.
+func GetStrings(key string) []string {
+	if Data == nil {
+		return nil
+	}
+	values, ok := Data.Get(key).([]interface{})
+	if !ok {
+		return nil
+	}
+	var strings []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			strings = append(strings, s)
+		}
+	}
+	return strings
+}
+
+// GetFloatPath returns the number stored under the key path, or fallback
+// when it is not set. Unlike the dotted keys above, path elements may
+// contain dots, e.g. model names.
+func GetFloatPath(path []string, fallback float64) float64 {
+	if Data == nil {
+		return fallback
+	}
+	switch value := Data.GetPath(path).(type) {
+	case float64:
+		return value
+	case int64:
+		return float64(value)
+	}
+	return fallback
+}
+
+// GetStringPath is GetString for a key path, see GetFloatPath.
+func GetStringPath(path []string, fallback string) string {
+	if Data == nil {
+		return fallback
+	}
+	if value, ok := Data.GetPath(path).(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetBoolPath returns the boolean stored under the key path, or fallback
+// when it is not set.
+func GetBoolPath(path []string, fallback bool) bool {
+	if Data == nil {
+		return fallback
+	}
+	if value, ok := Data.GetPath(path).(bool); ok {
+		return value
+	}
+	return fallback
+}
+
+// GetStringsPath is GetStrings for a key path, the second result tells
+// whether the list is set at all.
+func GetStringsPath(path []string) ([]string, bool) {
+	if Data == nil {
+		return nil, false
+	}
+	values, ok := Data.GetPath(path).([]interface{})
+	if !ok {
+		return nil, false
+	}
+	var strings []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			strings = append(strings, s)
+		}
+	}
+	return strings, true
+}
+
+// HasTable tells whether a table is stored under the key path.
+func HasTable(path []string) bool {
+	if Data == nil {
+		return false
+	}
+	_, ok := Data.GetPath(path).(*toml.Tree)
+	return ok
+}
+
+// GetIntMap returns the table stored at key as a map of integers, keys are
+// taken as they are so they may contain dots.
+func GetIntMap(key string) map[string]int {
+	if Data == nil {
+		return nil
+	}
+	table, ok := Data.Get(key).(*toml.Tree)
+	if !ok {
+		return nil
+	}
+	values := make(map[string]int)
+	for _, name := range table.Keys() {
+		if value, ok := table.GetPath([]string{name}).(int64); ok {
+			values[name] = int(value)
+		}
+	}
+	return values
+}
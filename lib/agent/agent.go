@@ -1,17 +1,23 @@
 package agent
 
 import (
+	"codexec/config"
 	"codexec/lib"
 	dockerexecutor "codexec/lib/dockerExecutor"
+	"codexec/lib/events"
+	"codexec/lib/executor"
+	"codexec/lib/llm"
+	pb "codexec/protos/go"
 	"codexec/types"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/tmc/langchaingo/llms"
-	"github.com/tmc/langchaingo/llms/openai"
 )
 
 const (
@@ -22,10 +28,13 @@ const (
 
 type AgentAdapter struct {
 	types.CoderAgent
+	Executor executor.Executor
+	// written are the files write_file saved since the last run_command
+	written []lib.SavedCodeBlock
 }
 
 func New() *AgentAdapter {
-	return &AgentAdapter{}
+	return &AgentAdapter{Executor: executor.New(dockerexecutor.NewDocker(dockerexecutor.Egress{}, nil, nil))}
 }
 
 func checkTermination(msg string) bool {
@@ -35,46 +44,161 @@ func checkTermination(msg string) bool {
 func (coder *AgentAdapter) StartTimer() {
 	timeStamp := time.Now().Unix()
 	coder.Instrumentation.TimeTaken = timeStamp
+	coder.Instrumentation.StartedAt = time.Now()
 }
 
 func (coder *AgentAdapter) EndTimer() {
 	timeStamp := time.Now().Unix()
 	coder.Instrumentation.TimeTaken = timeStamp - coder.Instrumentation.TimeTaken
+	coder.Instrumentation.WallTime = time.Since(coder.Instrumentation.StartedAt)
 }
 
-func (coder *AgentAdapter) TrackTokens(msg string) {
-	t := llms.CountTokens(coder.LLMModel, msg)
-	coder.Instrumentation.LLMTokens += t
+// TrackUsage records the tokens spent on one completion, it must be called
+// before the reply is added to the conversation that was sent.
+func (coder *AgentAdapter) TrackUsage(choice *llms.ContentChoice) {
+	usage := llm.Usage(coder.LLMModel, coder.Conversation, choice)
+	coder.Instrumentation.RoundUsage = append(coder.Instrumentation.RoundUsage, usage)
+	coder.Instrumentation.Usage = llm.Add(coder.Instrumentation.Usage, usage)
+	coder.Instrumentation.LLMTokens = coder.Instrumentation.Usage.TotalTokens
+}
+
+// Answer streams a plain LLM reply to the task logger without executing any
+// code, a failure of the model is an error event.
+func (coder *AgentAdapter) Answer() {
+	ctx := coder.Context
+	log.Printf("[CODER] (%d) answering query", coder.Task.Id)
+	model, err := llm.New(coder.LLMProvider, coder.LLMModel)
+	if err != nil {
+		log.Println(err)
+		coder.Events.Emit(events.Error("llm:init", err.Error()))
+		return
+	}
+
+	if coder.SystemPrompt != "" {
+		coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeSystem, coder.SystemPrompt))
+	}
+	coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, coder.UserPrompt))
+
+	output := coder.Logger.Writer()
+	completion, err := model.GenerateContent(ctx, coder.Conversation, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		_, err := output.Write(chunk)
+		return err
+	}))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("[CODER] (%d) llm request failed: %v", coder.Task.Id, err)
+			coder.Events.Emit(events.Error("llm:generate", err.Error()))
+		}
+		return
+	}
+
+	coder.Instrumentation.Rounds = 1
+	if len(completion.Choices) > 0 {
+		coder.TrackUsage(completion.Choices[0])
+	}
+}
+
+// executionOutput renders what the commands of a round wrote for the model,
+// each stream cut down to its last `agent.maxFeedbackBytes`.
+func executionOutput(response executor.Response) string {
+	max := config.GetInt("agent.maxFeedbackBytes", 4000)
+	output := fmt.Sprintf("stdout received : %s", tail(response.Stdout, max))
+	if response.Stderr != "" {
+		output += fmt.Sprintf("\nstderr received : %s", tail(response.Stderr, max))
+	}
+	return output
+}
+
+// fileQuota caps what the model of a task writes to its workspace.
+func fileQuota() *lib.FileQuota {
+	return &lib.FileQuota{
+		MaxFiles: config.GetInt("workspace.maxFiles", 200),
+		MaxBytes: int64(config.GetInt("workspace.maxWrittenMB", 16)) << 20,
+	}
+}
+
+// withRejected tells the model which files of its reply were not saved and
+// which of its edits did not apply.
+func withRejected(prompt string, rejected []lib.RejectedFile) string {
+	if len(rejected) == 0 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nThese files of your reply were not saved, keep files inside the working directory and send failed edits again against the current content of their file:\n")
+	for _, file := range rejected {
+		fmt.Fprintf(&b, "- %s: %s\n", file.Path, file.Reason)
+		if file.Hunk != "" {
+			fmt.Fprintf(&b, "```\n%s```\n", file.Hunk)
+		}
+	}
+	return b.String()
+}
+
+// tail returns the last max bytes of s, starting on a whole character.
+func tail(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+	start := len(s) - max
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+	return fmt.Sprintf("[... %d bytes truncated ...]\n%s", start, s[start:])
+}
+
+// stopped records why the task context ended, a cancel request or the task timeout.
+func (coder *AgentAdapter) stopped() {
+	if coder.Context.Err() == context.DeadlineExceeded {
+		log.Printf("[CODER] (%d) task timed out after %s", coder.Task.Id, coder.Limits.TaskTimeout)
+		coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_TIMED_OUT
+		coder.Events.Emit(events.Terminated("timed out"))
+		return
+	}
+	coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_CANCELLED
+	coder.Events.Emit(events.Terminated("cancelled"))
 }
 
 func (coder *AgentAdapter) Run() {
 	var roundTrip int32
 	ctx := coder.Context
 	log.Printf("[CODER] (%d) running task", coder.Task.Id)
-	llm, err := openai.New(openai.WithModel(coder.LLMModel))
+	model, err := llm.New(coder.LLMProvider, coder.LLMModel)
 	if err != nil {
 		log.Println(err)
+		coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_ERROR
+		coder.Events.Emit(events.Error("llm:init", err.Error()))
+		return
 	}
 
+	quota := fileQuota()
+
 	coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeSystem, coder.SystemPrompt))
 	coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, coder.UserPrompt))
 conversationStart:
 	select {
 	case <-ctx.Done():
 		log.Printf("[CODER] Agent stopping due to cancel request task(%d)", coder.Task.Id)
+		coder.stopped()
 		break
 	default:
 		coder.Logger.Println("-------------------------------------------------------------------------------------------------------")
 		coder.Logger.Println("[CODER] : Thinking ...")
 
-		completion, _ := llm.GenerateContent(ctx, coder.Conversation, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+		completion, err := model.GenerateContent(ctx, coder.Conversation, llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
 			return nil
 		}))
 
-		if ctx.Err() == nil {
-			fmt.Sprintln(ctx.Err(), err)
+		if ctx.Err() != nil {
+			coder.stopped()
+		} else if err != nil || len(completion.Choices) == 0 {
+			log.Printf("[CODER] (%d) llm request failed: %v", coder.Task.Id, err)
+			coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_ERROR
+			coder.Events.Emit(events.Error("llm:generate", fmt.Sprint(err)))
+		} else {
 			msgContent := completion.Choices[0].Content
-			coder.TrackTokens(msgContent)
+			coder.TrackUsage(completion.Choices[0])
+			coder.Events.Emit(events.LLMMessage(roundTrip, msgContent))
 
 			if !checkTermination(msgContent) {
 				coder.Logger.Print(red, italic, msgContent, reset)
@@ -82,42 +206,86 @@ conversationStart:
 				coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeAI, msgContent))
 				// Extract Code blocks
 				coder.Logger.Printf("[EXECUTOR] [retry: %d]: %s\n\n", roundTrip, "Extracting Code blocks")
-				lib.SplitIntoCodeBlocksAndSave(msgContent, coder.WorkingDirectory)
+				savedBlocks, rejected, err := lib.SplitIntoCodeBlocksAndSave(msgContent, coder.WorkingDirectory, quota)
+				if err != nil {
+					coder.Events.Emit(events.Error("executor:save", err.Error()))
+				}
+				for _, block := range savedBlocks {
+					coder.Events.Emit(events.FileExtracted(block.Path, block.Language, int64(block.Size)))
+				}
+				for _, file := range rejected {
+					coder.Events.Emit(events.FileRejected(file.Path, file.Reason, file.Hunk))
+				}
 				coder.Logger.Printf("[EXECUTOR] [retry: %d]: %s\n\n", roundTrip, "Executing Code blocks")
 
-				dockerExecuteParams := dockerexecutor.DockerExecuteParams{
+				dockerExecuteParams := executor.Params{
 					ContainerName:    coder.DockerContainerName,
 					WorkingDirectory: coder.WorkingDirectory,
 					DockerImage:      coder.DockerImage,
+					Limits:           coder.Limits,
+					Network:          coder.Network,
+					Events:           coder.Events,
 					Context:          coder.Context,
 					Cancel:           coder.Cancel,
+					Blocks:           savedBlocks,
+					Buildpack:        coder.Buildpack,
 				}
 
-				dockerExecReponse := dockerexecutor.Run(dockerExecuteParams)
+				dockerExecReponse, err := coder.Executor.Run(dockerExecuteParams)
+				coder.Instrumentation.ExitCode = dockerExecReponse.ExitCode
+				coder.Instrumentation.Network = dockerExecReponse.Network
+				if errors.Is(err, executor.ErrCancelled) {
+					coder.Instrumentation.Rounds = roundTrip + 1
+					coder.stopped()
+					return
+				}
+				// a timeout goes back to the model like a failing command,
+				// anything else means the code could not run at all
+				if err != nil && !errors.Is(err, executor.ErrTimeout) {
+					log.Printf("[CODER] (%d) execution failed: %v", coder.Task.Id, err)
+					coder.Instrumentation.Rounds = roundTrip + 1
+					coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_ERROR
+					coder.Events.Emit(events.Error(executor.ErrorCode(err), err.Error()))
+					return
+				}
 
 				// if dockerExecReponse.ExitCode != 0 {
 				// }
 
 				roundTrip = roundTrip + 1
+				coder.Instrumentation.Rounds = roundTrip
 				if roundTrip < coder.MaxRetry {
-					if dockerExecReponse.ExitCode != 0 {
+					coder.Events.Emit(events.Retry(roundTrip, coder.MaxRetry, int32(dockerExecReponse.ExitCode)))
+					if errors.Is(err, executor.ErrTimeout) {
+						coder.Logger.Printf("[EXECUTOR] [retry: %d]: timed out after %s \n\n", roundTrip, coder.Limits.CommandTimeout)
+						modificationPrompt := withRejected(fmt.Sprintf("The code ran longer than %s and was killed, give me another example that finishes in time, %s", coder.Limits.CommandTimeout, executionOutput(dockerExecReponse)), rejected)
+						coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, modificationPrompt))
+						coder.Events.Emit(events.Feedback(roundTrip, modificationPrompt))
+						goto conversationStart
+					} else if dockerExecReponse.ExitCode != 0 {
 						coder.Logger.Printf("[EXECUTOR] [retry: %d]: exit_code -  %d \n\n", roundTrip, dockerExecReponse.ExitCode)
 						coder.Logger.Printf("[EXECUTOR] [retry: %d]: %s\n\n", roundTrip, "Give me another example for Code")
-						modificationPrompt := fmt.Sprintf("Give me another example with modification, stdout received : %s", dockerExecReponse.Stdout)
+						modificationPrompt := withRejected(fmt.Sprintf("Give me another example with modification, %s", executionOutput(dockerExecReponse)), rejected)
 						coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, modificationPrompt))
+						coder.Events.Emit(events.Feedback(roundTrip, modificationPrompt))
 						goto conversationStart
 					} else {
 						coder.Logger.Printf("[EXECUTOR] [retry: %d]: exit_code - %d, stdout received -  %s  \n\n", roundTrip, dockerExecReponse.ExitCode, dockerExecReponse.Stdout)
-						modificationPrompt := fmt.Sprintf(" exit_code - %d, stdout received : %s", dockerExecReponse.ExitCode, dockerExecReponse.Stdout)
+						modificationPrompt := withRejected(fmt.Sprintf(" exit_code - %d, %s", dockerExecReponse.ExitCode, executionOutput(dockerExecReponse)), rejected)
 						coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, modificationPrompt))
+						coder.Events.Emit(events.Feedback(roundTrip, modificationPrompt))
 						goto conversationStart
 					}
 				} else {
 					coder.Logger.Println("terminate due to retries")
+					coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_MAX_RETRIES
+					coder.Events.Emit(events.Terminated("max retries"))
 				}
 			} else {
 				coder.Logger.Println(red, italic, msgContent, reset)
-				coder.Task.CompleteSignal <- true
+				coder.Instrumentation.Rounds = roundTrip + 1
+				coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_TERMINATED
+				coder.Events.Emit(events.Terminated("TERMINATE"))
 			}
 		}
 	}
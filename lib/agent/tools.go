@@ -0,0 +1,340 @@
+package agent
+
+import (
+	"codexec/config"
+	"codexec/lib"
+	"codexec/lib/events"
+	"codexec/lib/executor"
+	"codexec/lib/llm"
+	pb "codexec/protos/go"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// nudge is sent back when the model replies without calling a tool.
+const nudge = "Use the tools to do the task, call finish with the result once it is done."
+
+// tools are what the model can do in the tools agent mode. Paths are
+// relative to the workspace, the /app directory of the task container, so
+// files written with write_file are where run_command finds them.
+var tools = []llms.Tool{
+	tool("write_file", "Write a file of the workspace, creating its directories, and replace it when it exists.", map[string]any{
+		"path":    stringParameter("path of the file, relative to the workspace"),
+		"content": stringParameter("the whole content of the file"),
+	}, "path", "content"),
+	tool("read_file", "Read a file of the workspace.", map[string]any{
+		"path": stringParameter("path of the file, relative to the workspace"),
+	}, "path"),
+	tool("list_dir", "List the files and directories of a directory of the workspace.", map[string]any{
+		"path": stringParameter("path of the directory, relative to the workspace, . for the workspace itself"),
+	}),
+	tool("run_command", "Run a shell command in the workspace of the task container and return its exit code and output.", map[string]any{
+		"command": stringParameter("the command, run with sh -c"),
+	}, "command"),
+	tool("finish", "End the task once it is done, or cannot be done.", map[string]any{
+		"result": stringParameter("what was done and its outcome, for the user"),
+	}, "result"),
+}
+
+func tool(name string, description string, properties map[string]any, required ...string) llms.Tool {
+	parameters := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		parameters["required"] = required
+	}
+	return llms.Tool{
+		Type:     "function",
+		Function: &llms.FunctionDefinition{Name: name, Description: description, Parameters: parameters},
+	}
+}
+
+func stringParameter(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// toolArguments are the arguments of every tool, each uses its own.
+type toolArguments struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Command string `json:"command"`
+	Result  string `json:"result"`
+}
+
+// RunTools runs a task in the tools agent mode: the model calls tools
+// through the native tool calling of its provider until it calls finish or
+// runs out of replies, MaxRetry or `agent.maxToolRounds`.
+func (coder *AgentAdapter) RunTools() {
+	var round int32
+	ctx := coder.Context
+	log.Printf("[CODER] (%d) running task with tools", coder.Task.Id)
+	model, err := llm.New(coder.LLMProvider, coder.LLMModel)
+	if err != nil {
+		log.Println(err)
+		coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_ERROR
+		coder.Events.Emit(events.Error("llm:init", err.Error()))
+		return
+	}
+
+	maxRounds := coder.MaxRetry
+	if maxRounds <= 0 {
+		maxRounds = int32(config.GetInt("agent.maxToolRounds", 30))
+	}
+	quota := fileQuota()
+
+	coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeSystem, coder.SystemPrompt))
+	coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, coder.UserPrompt))
+	for round < maxRounds {
+		coder.Logger.Println("-------------------------------------------------------------------------------------------------------")
+		coder.Logger.Println("[CODER] : Thinking ...")
+
+		completion, err := model.GenerateContent(ctx, coder.Conversation, llms.WithTools(tools))
+		if ctx.Err() != nil {
+			coder.stopped()
+			return
+		}
+		if err != nil || len(completion.Choices) == 0 {
+			log.Printf("[CODER] (%d) llm request failed: %v", coder.Task.Id, err)
+			coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_ERROR
+			coder.Events.Emit(events.Error("llm:generate", fmt.Sprint(err)))
+			return
+		}
+		// some providers return the text and every tool call as choices of their own
+		var text []string
+		var calls []llms.ToolCall
+		for _, choice := range completion.Choices {
+			if choice.Content != "" {
+				text = append(text, choice.Content)
+			}
+			calls = append(calls, choice.ToolCalls...)
+		}
+		coder.TrackUsage(replyUsage(completion.Choices, text, calls))
+		coder.Instrumentation.Rounds = round + 1
+		if len(text) > 0 {
+			content := strings.Join(text, "\n")
+			coder.Logger.Print(red, italic, content, reset)
+			coder.Events.Emit(events.LLMMessage(round, content))
+		}
+
+		if len(calls) == 0 {
+			coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeAI, strings.Join(text, "\n")))
+			coder.Conversation = append(coder.Conversation, llms.TextParts(llms.ChatMessageTypeHuman, nudge))
+			round++
+			coder.Events.Emit(events.Feedback(round, nudge))
+			continue
+		}
+
+		// one call per message, the way every provider takes them back, the
+		// text of the reply goes with the first one. The call comes first,
+		// providers reading one part of a message read that one.
+		reply := strings.Join(text, "\n")
+		for _, call := range calls {
+			if call.FunctionCall == nil {
+				continue
+			}
+			name, arguments := call.FunctionCall.Name, call.FunctionCall.Arguments
+			coder.Logger.Printf("[TOOLS] [round: %d]: %s %s\n\n", round, name, arguments)
+			coder.Events.Emit(events.ToolCall(round, call.ID, name, arguments))
+			if call.Type == "" {
+				call.Type = "function"
+			}
+			message := llms.MessageContent{Role: llms.ChatMessageTypeAI, Parts: []llms.ContentPart{call}}
+			if reply != "" {
+				message.Parts = append(message.Parts, llms.TextContent{Text: reply})
+				reply = ""
+			}
+			coder.Conversation = append(coder.Conversation, message)
+
+			var args toolArguments
+			var err error
+			if strings.TrimSpace(arguments) != "" {
+				err = json.Unmarshal([]byte(arguments), &args)
+			}
+			if name == "finish" {
+				result := args.Result
+				if err != nil {
+					result = arguments
+				}
+				coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_TERMINATED
+				coder.Events.Emit(events.Finished(result))
+				return
+			}
+
+			var content string
+			if err != nil {
+				err = fmt.Errorf("the arguments are not a JSON object: %v", err)
+			} else {
+				content, err = coder.callTool(name, args, quota)
+			}
+			if errors.Is(err, executor.ErrCancelled) {
+				coder.stopped()
+				return
+			}
+			// like in Run, code that could not run at all ends the task
+			var executorError executor.ExecutorError
+			if errors.As(err, &executorError) && !errors.Is(err, executor.ErrTimeout) {
+				log.Printf("[CODER] (%d) execution failed: %v", coder.Task.Id, err)
+				coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_ERROR
+				coder.Events.Emit(events.Error(executor.ErrorCode(err), err.Error()))
+				return
+			}
+			if err != nil {
+				content = err.Error()
+			}
+
+			coder.Events.Emit(events.ToolResult(round, call.ID, name, content, err != nil))
+			coder.Conversation = append(coder.Conversation, llms.MessageContent{
+				Role:  llms.ChatMessageTypeTool,
+				Parts: []llms.ContentPart{llms.ToolCallResponse{ToolCallID: call.ID, Name: name, Content: content}},
+			})
+		}
+		round++
+	}
+
+	coder.Logger.Println("terminate due to max rounds")
+	coder.Instrumentation.Outcome = pb.Outcome_OUTCOME_MAX_RETRIES
+	coder.Events.Emit(events.Terminated("max retries"))
+}
+
+// replyUsage returns the choice usage is tracked with for a reply spread
+// over choices. Providers reporting usage put the total for the reply on
+// each choice, it is taken from the first one carrying it. Otherwise the
+// tokens are counted over the text and tool calls of every choice.
+func replyUsage(choices []*llms.ContentChoice, text []string, calls []llms.ToolCall) *llms.ContentChoice {
+	for _, choice := range choices {
+		if llm.Reported(choice) {
+			return choice
+		}
+	}
+	content := text
+	for _, call := range calls {
+		if call.FunctionCall != nil {
+			content = append(content, call.FunctionCall.Name, call.FunctionCall.Arguments)
+		}
+	}
+	return &llms.ContentChoice{Content: strings.Join(content, "\n")}
+}
+
+// callTool runs one tool call other than finish and returns what goes back
+// to the model.
+func (coder *AgentAdapter) callTool(name string, args toolArguments, quota *lib.FileQuota) (string, error) {
+	switch name {
+	case "write_file":
+		if err := os.MkdirAll(coder.WorkingDirectory, os.ModePerm); err != nil {
+			return "", err
+		}
+		path, err := lib.SaveFile(coder.WorkingDirectory, args.Path, []byte(args.Content), quota)
+		if err != nil {
+			return "", err
+		}
+		coder.written = append(coder.written, lib.SavedCodeBlock{Path: path, Size: len(args.Content)})
+		coder.Events.Emit(events.FileExtracted(path, "", int64(len(args.Content))))
+		return fmt.Sprintf("wrote %d bytes to %s", len(args.Content), path), nil
+	case "read_file":
+		return coder.readFile(args.Path)
+	case "list_dir":
+		return coder.listDir(args.Path)
+	case "run_command":
+		return coder.runCommand(args.Command)
+	}
+	return "", fmt.Errorf("unknown tool %q", name)
+}
+
+// readFile returns the start of a workspace file, `agent.maxFeedbackBytes`
+// of it at most.
+func (coder *AgentAdapter) readFile(name string) (string, error) {
+	path, err := lib.ConfinedPath(coder.WorkingDirectory, name)
+	if err != nil {
+		return "", workspaceError(name, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", workspaceError(name, err)
+	}
+	max := config.GetInt("agent.maxFeedbackBytes", 4000)
+	if max > 0 && len(content) > max {
+		return fmt.Sprintf("%s\n[... %d bytes truncated ...]", content[:max], len(content)-max), nil
+	}
+	return string(content), nil
+}
+
+func (coder *AgentAdapter) listDir(name string) (string, error) {
+	if name == "" {
+		name = "."
+	}
+	if err := os.MkdirAll(coder.WorkingDirectory, os.ModePerm); err != nil {
+		return "", err
+	}
+	path, err := lib.ConfinedPath(coder.WorkingDirectory, name)
+	if err != nil {
+		return "", workspaceError(name, err)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", workspaceError(name, err)
+	}
+	if len(entries) == 0 {
+		return "the directory is empty", nil
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		if entry.IsDir() {
+			fmt.Fprintf(&b, "%s/\n", entry.Name())
+		} else if info, err := entry.Info(); err == nil {
+			fmt.Fprintf(&b, "%s (%d bytes)\n", entry.Name(), info.Size())
+		}
+	}
+	return b.String(), nil
+}
+
+// workspaceError describes a failure to open name without the host path of
+// the workspace.
+func workspaceError(name string, err error) error {
+	var pathError *fs.PathError
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%s does not exist", name)
+	case errors.As(err, &pathError):
+		return fmt.Errorf("%s: %v", name, pathError.Err)
+	}
+	return err
+}
+
+// runCommand runs command in the task container, a timeout or a non-zero
+// exit code goes back to the model like any output.
+func (coder *AgentAdapter) runCommand(command string) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", errors.New("the command is empty")
+	}
+	if err := os.MkdirAll(coder.WorkingDirectory, os.ModePerm); err != nil {
+		return "", err
+	}
+	response, err := coder.Executor.Run(executor.Params{
+		ContainerName:    coder.DockerContainerName,
+		WorkingDirectory: coder.WorkingDirectory,
+		DockerImage:      coder.DockerImage,
+		Limits:           coder.Limits,
+		Network:          coder.Network,
+		Events:           coder.Events,
+		Context:          coder.Context,
+		Cancel:           coder.Cancel,
+		Buildpack:        coder.Buildpack,
+		Blocks:           coder.written,
+		Commands:         []string{command},
+	})
+	coder.written = nil
+	coder.Instrumentation.ExitCode = response.ExitCode
+	coder.Instrumentation.Network = response.Network
+	if errors.Is(err, executor.ErrTimeout) {
+		return fmt.Sprintf("the command ran longer than %s and was killed, %s", coder.Limits.CommandTimeout, executionOutput(response)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("exit_code - %d, %s", response.ExitCode, executionOutput(response)), nil
+}
@@ -0,0 +1,200 @@
+package lib
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteTarGz writes the regular files below dir as a gzipped tarball,
+// symlinks are skipped so nothing outside of dir ends up in it.
+func WriteTarGz(w io.Writer, dir string) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	err := walkRegularFiles(dir, func(path string, name string, info fs.FileInfo) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		return copyFile(tw, path)
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// WriteZip writes the regular files below dir as a zip archive.
+func WriteZip(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+
+	err := walkRegularFiles(dir, func(path string, name string, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		header.Method = zip.Deflate
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		return copyFile(entry, path)
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func walkRegularFiles(dir string, fn func(path string, name string, info fs.FileInfo) error) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return fn(path, filepath.ToSlash(rel), info)
+	})
+}
+
+func copyFile(w io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// ExtractTarGz unpacks a gzipped tarball into dir and returns how many files
+// it wrote and their total size. Entries other than files and directories
+// are skipped, an entry pointing outside of dir or more than limit bytes of
+// content fail the extraction.
+func ExtractTarGz(r io.Reader, dir string, limit int64) (int, int64, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer gz.Close()
+
+	x := &extractor{dir: dir, limit: limit}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return x.files, x.written, nil
+		}
+		if err != nil {
+			return x.files, x.written, err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(header.Name)
+		case tar.TypeReg:
+			err = x.file(header.Name, header.FileInfo().Mode(), tr)
+		}
+		if err != nil {
+			return x.files, x.written, err
+		}
+	}
+}
+
+// ExtractZip unpacks a zip archive of the given size into dir, like ExtractTarGz.
+func ExtractZip(r io.ReaderAt, size int64, dir string, limit int64) (int, int64, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	x := &extractor{dir: dir, limit: limit}
+	for _, entry := range zr.File {
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.mkdir(entry.Name)
+		case mode.IsRegular():
+			err = x.zipFile(entry)
+		}
+		if err != nil {
+			return x.files, x.written, err
+		}
+	}
+	return x.files, x.written, nil
+}
+
+type extractor struct {
+	dir     string
+	limit   int64
+	written int64
+	files   int
+}
+
+func (x *extractor) mkdir(name string) error {
+	rel, err := RelativePath(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(filepath.Join(x.dir, rel), os.ModePerm)
+}
+
+func (x *extractor) zipFile(entry *zip.File) error {
+	content, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return x.file(entry.Name, entry.Mode(), content)
+}
+
+// file writes one archive entry, only the executable bits of mode are kept.
+func (x *extractor) file(name string, mode fs.FileMode, content io.Reader) error {
+	rel, err := RelativePath(name)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(x.dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644|(mode&0111))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	n, err := io.CopyN(file, content, x.limit-x.written+1)
+	x.written += n
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if x.written > x.limit {
+		return fmt.Errorf("archive content exceeds %d bytes", x.limit)
+	}
+	x.files++
+	return nil
+}
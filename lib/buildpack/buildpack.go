@@ -0,0 +1,152 @@
+// Package buildpack knows how code is run in the images tasks ask for:
+// which languages an image runs, how their dependencies are installed and
+// how a source file is started.
+//
+// Buildpacks are declared by a `buildpack.toml` next to the Dockerfile of a
+// directory under `images.buildpacks`, whose image is code.buildpack.<name>,
+// or by a [buildpacks.<name>] table of config.toml for any other image. A
+// table of config.toml wins over a directory of the same name.
+package buildpack
+
+import (
+	"codexec/config"
+	"codexec/types"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// imagePrefix names the images built from a buildpack directory.
+const imagePrefix = "code.buildpack."
+
+// Registry is the set of known buildpacks.
+type Registry []types.Buildpack
+
+// Load reads the buildpacks declared under dir and in config.toml.
+func Load(dir string) (Registry, error) {
+	packs := make(map[string]types.Buildpack)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		tree, err := toml.LoadFile(filepath.Join(dir, entry.Name(), "buildpack.toml"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pack := types.Buildpack{Image: imagePrefix + entry.Name()}
+		if err := tree.Unmarshal(&pack); err != nil {
+			return nil, fmt.Errorf("buildpack %s: %w", entry.Name(), err)
+		}
+		pack.Name = entry.Name()
+		packs[pack.Name] = pack
+	}
+
+	if config.Data != nil {
+		if tables, ok := config.Data.Get("buildpacks").(*toml.Tree); ok {
+			for _, name := range tables.Keys() {
+				table, ok := tables.GetPath([]string{name}).(*toml.Tree)
+				if !ok {
+					continue
+				}
+				var pack types.Buildpack
+				if err := table.Unmarshal(&pack); err != nil {
+					return nil, fmt.Errorf("buildpack %s: %w", name, err)
+				}
+				pack.Name = name
+				packs[name] = pack
+			}
+		}
+	}
+
+	var registry Registry
+	for _, pack := range packs {
+		registry = append(registry, pack)
+	}
+	sort.Slice(registry, func(i, j int) bool { return registry[i].Name < registry[j].Name })
+	return registry, nil
+}
+
+// ForImage returns the buildpack running image, nil when there is none.
+func (r Registry) ForImage(image string) *types.Buildpack {
+	for i := range r {
+		if r[i].Image == image {
+			return &r[i]
+		}
+	}
+	return nil
+}
+
+// RunCommands returns the commands compiling, when pack does, and running
+// file with pack.
+func RunCommands(pack *types.Buildpack, file string) []string {
+	out := strings.TrimSuffix(file, filepath.Ext(file))
+	if out == file {
+		out += ".out"
+	}
+	if !strings.Contains(out, "/") {
+		out = "./" + out
+	}
+	expand := strings.NewReplacer("{file}", Quote(file), "{out}", Quote(out))
+
+	var commands []string
+	if pack.Build != "" {
+		commands = append(commands, expand.Replace(pack.Build))
+	}
+	return append(commands, expand.Replace(pack.Run))
+}
+
+// Env returns the environment commands run with in the image of pack, the
+// env of Languages with that of pack on top.
+func Env(pack *types.Buildpack) []string {
+	var env []string
+	index := make(map[string]int)
+	add := func(values []string) {
+		for _, value := range values {
+			key, _, _ := strings.Cut(value, "=")
+			if i, ok := index[key]; ok {
+				env[i] = value
+				continue
+			}
+			index[key] = len(env)
+			env = append(env, value)
+		}
+	}
+	for _, language := range Languages {
+		add(language.Env)
+	}
+	if pack != nil {
+		add(pack.Env)
+	}
+	return env
+}
+
+// Quote quotes s for sh unless it is plain enough to go as it is.
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func hasAny(dir string, names []string) bool {
+	for _, name := range names {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && info.Mode().IsRegular() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package buildpack
+
+import (
+	"codexec/config"
+	"codexec/lib"
+	"codexec/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+)
+
+func TestLoadAndPlan(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "python"), os.ModePerm)
+	os.WriteFile(filepath.Join(dir, "python", "buildpack.toml"), []byte(`
+languages = ["python"]
+extensions = [".py"]
+dependencyFiles = ["requirements.txt"]
+install = "pip install -r requirements.txt"
+run = "python {file}"
+`), 0644)
+	tree, err := toml.Load(`
+[buildpacks.node]
+  image = "node:20-alpine"
+  extensions = [".js"]
+  run = "node {file}"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Data = tree
+	t.Cleanup(func() { config.Data = nil })
+
+	registry, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	python := registry.ForImage("code.buildpack.python")
+	if python == nil || python.Name != "python" || python.Run != "python {file}" {
+		t.Fatalf("python buildpack = %+v", python)
+	}
+	if node := registry.ForImage("node:20-alpine"); node == nil || node.Name != "node" {
+		t.Fatalf("node buildpack = %+v", node)
+	}
+
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "requirements.txt"), []byte("requests\n"), 0644)
+	blocks := []lib.SavedCodeBlock{
+		{Path: "codeblock_1.py", Language: "python"},
+		{Path: "codeblock_2.py", Language: "python"},
+		{Path: "notes.txt", Language: "text"},
+	}
+	commands := Plan(workspace, blocks, python)
+	want := []string{"pip install -r requirements.txt", "python codeblock_1.py", "python codeblock_2.py"}
+	if strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+
+	// a shell block running the files takes over from running them
+	os.WriteFile(filepath.Join(workspace, "codeblock_3.sh"), []byte("python codeblock_1.py && python codeblock_2.py\n"), 0644)
+	blocks = append(blocks, lib.SavedCodeBlock{Path: "codeblock_3.sh", Language: "bash"})
+	commands = Plan(workspace, blocks, python)
+	want = []string{"pip install -r requirements.txt", "sh codeblock_3.sh"}
+	if strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+func TestPlanRunsInstallsFirstAndEachLanguage(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "codeblock_3.sh"), []byte("# deps\npip install requests\nnpm i left-pad\n"), 0644)
+
+	blocks := []lib.SavedCodeBlock{
+		{Path: "util.py", Language: "python"},
+		{Path: "main.py", Language: "python"},
+		{Path: "hello.c", Language: "c"},
+		{Path: "codeblock_3.sh", Language: "bash"},
+		{Path: "package.json", Language: "json"},
+		{Path: "my prog.js", Language: "javascript"},
+	}
+	want := []string{
+		"sh codeblock_3.sh",
+		"npm install --no-audit --no-fund",
+		"python3 main.py",
+		"cc -O2 -o ./hello hello.c",
+		"./hello",
+		"node 'my prog.js'",
+	}
+	if commands := Plan(workspace, blocks, nil); strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+func TestPlanHonoursRunAndSkip(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "setup.sh"), []byte("echo setting up\n"), 0644)
+
+	blocks := []lib.SavedCodeBlock{
+		{Path: "setup.sh", Language: "bash"},
+		{Path: "check.py", Language: "python", Attributes: map[string]string{"run": "true"}},
+		{Path: "example.sh", Language: "bash", Attributes: map[string]string{"skip": "true"}},
+	}
+	want := []string{"sh setup.sh", "python3 check.py"}
+	if commands := Plan(workspace, blocks, nil); strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+func TestPlanKeepsTheOrderOfTheReply(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "test.sh"), []byte("curl -s localhost:8000\n"), 0644)
+	os.WriteFile(filepath.Join(workspace, "deps.sh"), []byte("pip install flask && python app.py\n"), 0644)
+
+	blocks := []lib.SavedCodeBlock{
+		{Path: "app.py", Language: "python"},
+		{Path: "test.sh", Language: "sh"},
+	}
+	want := []string{"python3 app.py", "sh test.sh"}
+	if commands := Plan(workspace, blocks, nil); strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+
+	// a chained command is no install step and runs the app itself
+	blocks = append(blocks, lib.SavedCodeBlock{Path: "deps.sh", Language: "sh"})
+	want = []string{"sh test.sh", "sh deps.sh"}
+	if commands := Plan(workspace, blocks, nil); strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+// TestShippedBuildpacksWriteToTheWorkspace checks the buildpacks shipped in
+// config.toml and code.buildpacks, and the defaults of Languages, keep what
+// they download and build below /app, the one place a container user can
+// write to and run from besides /tmp.
+func TestShippedBuildpacksWriteToTheWorkspace(t *testing.T) {
+	tree, err := toml.LoadFile(filepath.Join("..", "..", "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Data = tree
+	t.Cleanup(func() { config.Data = nil })
+	registry, err := Load(filepath.Join("..", "..", "code.buildpacks"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	below := func(env []string, key string, dir string) bool {
+		for _, value := range env {
+			if path, ok := strings.CutPrefix(value, key+"="); ok {
+				return path == dir || strings.HasPrefix(path, dir+"/")
+			}
+		}
+		return false
+	}
+	tests := []struct {
+		name string
+		pack *types.Buildpack
+	}{
+		{"go", registry.ForImage("golang:1.22-alpine")},
+		{"python", registry.ForImage("code.buildpack.python")},
+		{"defaults", nil},
+	}
+	for _, test := range tests {
+		if test.name != "defaults" && test.pack == nil {
+			t.Fatalf("%s: no buildpack", test.name)
+		}
+		env := Env(test.pack)
+		for _, key := range []string{"GOPATH", "GOCACHE", "PYTHONUSERBASE"} {
+			if !below(env, key, "/app") {
+				t.Errorf("%s: %s not below the workspace in %v", test.name, key, env)
+			}
+		}
+		// go run execs what it builds from GOTMPDIR, the tmpfs is mounted exec
+		if !below(env, "GOTMPDIR", "/tmp") {
+			t.Errorf("%s: GOTMPDIR not on /tmp in %v", test.name, env)
+		}
+	}
+
+	// the plan installs and runs with the commands the env is meant for
+	workspace := t.TempDir()
+	blocks := []lib.SavedCodeBlock{{Path: "requirements.txt"}, {Path: "main.py", Language: "python"}}
+	want := []string{"pip install --quiet --user --no-cache-dir -r requirements.txt", "python main.py"}
+	if commands := Plan(workspace, blocks, registry.ForImage("code.buildpack.python")); strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+	blocks = []lib.SavedCodeBlock{{Path: "go.mod"}, {Path: "main.go", Language: "go"}}
+	want = []string{"go mod download", "go run main.go"}
+	if commands := Plan(workspace, blocks, registry.ForImage("golang:1.22-alpine")); strings.Join(commands, "|") != strings.Join(want, "|") {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+func TestEnvLetsThePackOverrideTheDefaults(t *testing.T) {
+	env := Env(&types.Buildpack{Env: []string{"GOPATH=/app/go", "NODE_ENV=production"}})
+	want := map[string]string{"GOPATH": "/app/go", "NODE_ENV": "production", "PYTHONUSERBASE": "/app/.local"}
+	seen := make(map[string]bool)
+	for _, value := range env {
+		key, got, _ := strings.Cut(value, "=")
+		if seen[key] {
+			t.Errorf("%s set twice in %v", key, env)
+		}
+		seen[key] = true
+		if w, ok := want[key]; ok && got != w {
+			t.Errorf("%s = %q, want %q", key, got, w)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package buildpack
+
+import (
+	"codexec/lib"
+	"codexec/types"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Languages is how source files are run when the buildpack of the image
+// does not cover their language. The image still has to ship the tools,
+// a missing one fails like any other command and goes back to the model.
+// The root filesystem of a container is read-only and owned by root, what
+// the tools download, install and build goes to the workspace.
+var Languages = Registry{
+	{Name: "shell", Languages: []string{"bash", "sh", "shell", "zsh"}, Extensions: []string{".sh"}, Run: "sh {file}"},
+	{Name: "python", Languages: []string{"python", "py", "python3"}, Extensions: []string{".py"},
+		DependencyFiles: []string{"requirements.txt"}, Install: "pip install --quiet --user --no-cache-dir -r requirements.txt", Run: "python3 {file}",
+		Env: []string{"PYTHONUSERBASE=/app/.local"}},
+	{Name: "javascript", Languages: []string{"javascript", "js", "node"}, Extensions: []string{".js"},
+		DependencyFiles: []string{"package.json"}, Install: "npm install --no-audit --no-fund", Run: "node {file}"},
+	{Name: "ruby", Languages: []string{"ruby", "rb"}, Extensions: []string{".rb"},
+		DependencyFiles: []string{"Gemfile"}, Install: "bundle install", Run: "ruby {file}"},
+	{Name: "go", Languages: []string{"go", "golang"}, Extensions: []string{".go"},
+		DependencyFiles: []string{"go.mod"}, Install: "go mod download", Run: "go run {file}",
+		Env: []string{"GOPATH=/app/.go", "GOCACHE=/app/.go/cache", "GOTMPDIR=/tmp", "GOFLAGS=-modcacherw"}},
+	{Name: "java", Languages: []string{"java"}, Extensions: []string{".java"}, Run: "java {file}"},
+	{Name: "c", Languages: []string{"c"}, Extensions: []string{".c"}, Build: "cc -O2 -o {out} {file}", Run: "{out}"},
+	{Name: "c++", Languages: []string{"c++", "cpp", "cxx"}, Extensions: []string{".cpp", ".cc", ".cxx"}, Build: "c++ -O2 -o {out} {file}", Run: "{out}"},
+}
+
+// installCommand matches a line that only installs packages, e.g. `pip
+// install requests`, and not one chaining other commands after it.
+var installCommand = regexp.MustCompile("^(sudo\\s+)?(pip3?|python3?\\s+-m\\s+pip|npm|yarn|pnpm|gem|bundle|go|cargo|apk|apt-get|apt)\\s+(install|i|add|get|mod\\s+download)(\\s+[^;&|<>$`()]*)?$")
+
+// entryPoints are the file names, without extension, of the one source
+// file to run among several of a language.
+var entryPoints = map[string]bool{"main": true, "app": true, "index": true, "Main": true}
+
+// Plan returns the commands running the blocks of one reply in dir: the
+// install steps first, dependency manifests through the install command of
+// their language and shell blocks that only install packages, then the
+// rest in the order of the reply. Shell blocks run with sh. Source blocks
+// run at their place in the reply unless a shell block of the reply names
+// their file, and of several files of a language only the main, app or
+// index one. A block marked run in its info string always runs, one marked
+// skip or norun never does.
+//
+// pack is the buildpack of the image, it takes precedence over Languages
+// for what it covers and its install command also runs when one of its
+// dependency files is left in the workspace from an earlier round.
+func Plan(dir string, blocks []lib.SavedCodeBlock, pack *types.Buildpack) []string {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	var install []string
+	var rest, sources []lib.SavedCodeBlock
+	var scripts []string
+	installed := make(map[string]bool)
+
+	addInstall := func(command string) {
+		if !installed[command] {
+			installed[command] = true
+			install = append(install, command)
+		}
+	}
+
+	if pack != nil && pack.Install != "" && hasAny(dir, pack.DependencyFiles) {
+		addInstall(pack.Install)
+	}
+	for _, block := range blocks {
+		if run, ok := lib.Runs(block.Attributes); ok && !run {
+			continue
+		}
+		if manifest := manifestOf(block.Path, pack); manifest != nil {
+			addInstall(manifest.Install)
+			continue
+		}
+		language := languageOf(block, pack)
+		switch {
+		case language == nil:
+			// notes, data and config files are only saved
+		case language.Name == "shell":
+			if installsOnly(dir, block.Path) {
+				addInstall(fmt.Sprintf("sh %s", Quote(block.Path)))
+				continue
+			}
+			script, _ := readFile(dir, block.Path)
+			scripts = append(scripts, script)
+			rest = append(rest, block)
+		default:
+			sources = append(sources, block)
+			rest = append(rest, block)
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, block := range entries(sources, pack) {
+		if !namedBy(scripts, block.Path) {
+			selected[block.Path] = true
+		}
+	}
+	commands := install
+	for _, block := range rest {
+		language := languageOf(block, pack)
+		if language.Name == "shell" {
+			commands = append(commands, fmt.Sprintf("sh %s", Quote(block.Path)))
+			continue
+		}
+		if run, _ := lib.Runs(block.Attributes); run || selected[block.Path] {
+			commands = append(commands, RunCommands(language, block.Path)...)
+		}
+	}
+	return commands
+}
+
+// namedBy reports whether one of scripts names the file name, as a whole
+// word, so it runs the file itself.
+func namedBy(scripts []string, name string) bool {
+	word := regexp.MustCompile(`(^|[\s/'"])` + regexp.QuoteMeta(path.Base(name)) + `($|[\s'";&|)])`)
+	for _, script := range scripts {
+		if word.MatchString(script) {
+			return true
+		}
+	}
+	return false
+}
+
+// languageOf returns how block runs, by its info string or else by its
+// extension, nil when it is no code Plan knows to run.
+func languageOf(block lib.SavedCodeBlock, pack *types.Buildpack) *types.Buildpack {
+	tag := strings.ToLower(strings.TrimSpace(block.Language))
+	if fields := strings.Fields(tag); len(fields) > 0 {
+		tag = fields[0]
+	}
+	extension := path.Ext(block.Path)
+
+	if pack != nil && pack.Run != "" && (contains(pack.Languages, tag) || contains(pack.Extensions, extension)) {
+		return pack
+	}
+	// a block without a tag is saved as a shell script
+	if tag != "" {
+		for i := range Languages {
+			if contains(Languages[i].Languages, tag) {
+				return &Languages[i]
+			}
+		}
+	}
+	for i := range Languages {
+		if contains(Languages[i].Extensions, extension) {
+			return &Languages[i]
+		}
+	}
+	return nil
+}
+
+// manifestOf returns the language whose dependency manifest name is, nil
+// when it is none.
+func manifestOf(name string, pack *types.Buildpack) *types.Buildpack {
+	if pack != nil && pack.Install != "" && contains(pack.DependencyFiles, name) {
+		return pack
+	}
+	for i := range Languages {
+		if Languages[i].Install != "" && contains(Languages[i].DependencyFiles, name) {
+			return &Languages[i]
+		}
+	}
+	return nil
+}
+
+// entries returns the source blocks to run, the entry point of a language
+// when several blocks share it.
+func entries(sources []lib.SavedCodeBlock, pack *types.Buildpack) []lib.SavedCodeBlock {
+	hasEntry := make(map[string]bool)
+	for _, block := range sources {
+		if isEntry(block.Path) {
+			hasEntry[languageOf(block, pack).Name] = true
+		}
+	}
+	var run []lib.SavedCodeBlock
+	for _, block := range sources {
+		if !hasEntry[languageOf(block, pack).Name] || isEntry(block.Path) {
+			run = append(run, block)
+		}
+	}
+	return run
+}
+
+func isEntry(name string) bool {
+	base := path.Base(name)
+	return entryPoints[strings.TrimSuffix(base, path.Ext(base))]
+}
+
+// installsOnly reports whether every command of the shell script name
+// installs packages.
+func installsOnly(dir string, name string) bool {
+	content, err := readFile(dir, name)
+	if err != nil {
+		return false
+	}
+	commands := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !installCommand.MatchString(line) {
+			return false
+		}
+		commands++
+	}
+	return commands > 0
+}
+
+func readFile(dir string, name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	return string(content), err
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,142 +2,178 @@ package lib
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
 	"strings"
+	"unicode"
 )
 
-type CodeblockFile struct {
-	Name   string
-	Number int
+type SavedCodeBlock struct {
+	Path     string
+	Language string
+	Size     int
+	// Attributes are the attributes of the info string of the block, see
+	// CodeBlock.
+	Attributes map[string]string
 }
 
-func GenerateCommands(dir string) []string {
-	cmds := []string{}
-	dirPath := dir
+// RejectedFile is a code block that was not saved, or a hunk of an edit
+// that was not applied, and why.
+type RejectedFile struct {
+	Path   string
+	Reason string
+	// Hunk is the hunk or search/replace block as the model wrote it
+	Hunk string
+}
 
-	// Read the directory
-	files, err := ioutil.ReadDir(dirPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading directory: %v\n", err)
-		os.Exit(1)
-	}
+// FileQuota caps the files the replies of one task write to its workspace,
+// a file written again counts once, with its latest size. Zero is unlimited.
+type FileQuota struct {
+	MaxFiles int
+	MaxBytes int64
+	sizes    map[string]int64
+	total    int64
+}
 
-	// Compile the regex pattern
-	pattern := regexp.MustCompile(`^codeblock_(\d+)\.sh$`)
-
-	var codeblockFiles []CodeblockFile
-	for _, file := range files {
-		if !file.IsDir() {
-			matches := pattern.FindStringSubmatch(file.Name())
-			if matches != nil {
-				number, _ := strconv.Atoi(matches[1])
-				codeblockFiles = append(codeblockFiles, CodeblockFile{
-					Name:   file.Name(),
-					Number: number,
-				})
-			}
-		}
+// admit fails when writing size bytes to name would exceed the quota.
+func (q *FileQuota) admit(name string, size int64) error {
+	previous, written := q.sizes[name]
+	if q.MaxFiles > 0 && !written && len(q.sizes) >= q.MaxFiles {
+		return fmt.Errorf("the task already wrote the most files allowed, %d", q.MaxFiles)
 	}
+	if q.MaxBytes > 0 && q.total-previous+size > q.MaxBytes {
+		return fmt.Errorf("the task would write more than the %d bytes allowed", q.MaxBytes)
+	}
+	return nil
+}
 
-	// Sort the matching files based on the number
-	sort.Slice(codeblockFiles, func(i, j int) bool {
-		return codeblockFiles[i].Number < codeblockFiles[j].Number
-	})
-
-	for _, file := range codeblockFiles {
-		cmd := fmt.Sprintf("sh %s", file.Name)
-		cmds = append(cmds, cmd)
+func (q *FileQuota) add(name string, size int64) {
+	if q.sizes == nil {
+		q.sizes = make(map[string]int64)
 	}
-	return cmds
+	q.total += size - q.sizes[name]
+	q.sizes[name] = size
+}
+
+func (q *FileQuota) remove(name string) {
+	q.total -= q.sizes[name]
+	delete(q.sizes, name)
 }
 
-func SplitIntoCodeBlocksAndSave(input string, outputDir string) error {
-	lines := strings.Split(input, "\n")
-	var currentBlock strings.Builder
-	inCodeBlock := false
-	language := ""
-	blockCount := 0
+// SplitIntoCodeBlocksAndSave saves the code blocks of input below
+// outputDir. Blocks naming a file outside of it, through a symlink or past
+// quota, which may be nil, are not saved but returned as rejected.
+//
+// Unified diffs and search/replace blocks are applied to the files they
+// name instead, which are returned as saved, and the hunks that did not
+// match as rejected.
+func SplitIntoCodeBlocksAndSave(input string, outputDir string, quota *FileQuota) ([]SavedCodeBlock, []RejectedFile, error) {
+	var saved []SavedCodeBlock
+	var rejected []RejectedFile
 
 	err := os.MkdirAll(outputDir, os.ModePerm)
 	if err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
+		return nil, nil, fmt.Errorf("failed to create output directory: %v", err)
 	}
 
-	for _, line := range lines {
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "```") {
-			if inCodeBlock {
-				// End of a code block
-				blockCount++
-				err := saveCodeBlock(outputDir, language, currentBlock.String(), blockCount)
-				if err != nil {
-					return err
-				}
-				currentBlock.Reset()
-				inCodeBlock = false
-				language = ""
-			} else {
-				// Start of a code block
-				inCodeBlock = true
-				language = strings.TrimPrefix(trimmedLine, "```")
-			}
-		} else if inCodeBlock {
-			currentBlock.WriteString(line + "\n")
+	for i, codeBlock := range ParseCodeBlocks(input) {
+		if isEdit(codeBlock) {
+			edited, failed := applyEdits(outputDir, codeBlock, quota)
+			saved = append(saved, edited...)
+			rejected = append(rejected, failed...)
+			continue
 		}
-	}
-
-	// Handle case where the last code block isn't closed
-	if inCodeBlock {
-		blockCount++
-		err := saveCodeBlock(outputDir, language, currentBlock.String(), blockCount)
+		block, err := saveCodeBlock(outputDir, codeBlock, i+1, quota)
 		if err != nil {
-			return err
+			log.Printf("[EXECUTOR] : Rejected file %q: %v\n", block.Path, err)
+			rejected = append(rejected, RejectedFile{Path: block.Path, Reason: err.Error()})
+			continue
 		}
+		saved = append(saved, block)
 	}
 
-	return nil
+	return saved, rejected, nil
 }
 
-func extractFileName(content string) string {
-	filename := ""
-	pattern := `(?m)^#\s*filename:\s*(\S+)`
+func saveCodeBlock(outputDir string, block CodeBlock, blockCount int, quota *FileQuota) (SavedCodeBlock, error) {
+	filename := block.Filename()
+	if len(filename) == 0 {
+		filename = fmt.Sprintf("codeblock_%d%s", blockCount, getExtensionForLanguage(block.Language))
+	}
+	saved := SavedCodeBlock{Path: filename, Language: block.Language, Size: len(block.Body), Attributes: block.Attributes}
 
-	re := regexp.MustCompile(pattern)
-	match := re.FindStringSubmatch(content)
-	if len(match) > 1 {
-		filename = match[1]
+	path, err := SaveFile(outputDir, filename, []byte(block.Body), quota)
+	if err != nil {
+		return saved, err
 	}
-	return filename
+	saved.Path = path
+
+	log.Printf("[EXECUTOR] : Created file: %s\n", filepath.Join(outputDir, filepath.FromSlash(path)))
+	return saved, nil
 }
 
-func saveCodeBlock(outputDir, language, content string, blockCount int) error {
-	extension := getExtensionForLanguage(language)
-	filename := extractFileName(content)
-	if len(filename) == 0 {
-		filename = fmt.Sprintf("codeblock_%d%s", blockCount, extension)
+// SaveFile writes content to the file the model named below root, within
+// quota, which may be nil. It returns the cleaned slash separated path.
+func SaveFile(root string, name string, content []byte, quota *FileQuota) (string, error) {
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return "", fmt.Errorf("path %q has control characters", name)
+	}
+	cleaned, err := RelativePath(name)
+	if err != nil {
+		return "", err
+	}
+	// files are named the way the commands of the plan refer to them
+	path := filepath.ToSlash(cleaned)
+	if quota != nil {
+		if err := quota.admit(path, int64(len(content))); err != nil {
+			return "", err
+		}
+	}
+	if err := WriteConfined(root, path, content, 0644); err != nil {
+		return "", err
 	}
-	filepath := filepath.Join(outputDir, filename)
+	if quota != nil {
+		quota.add(path, int64(len(content)))
+	}
+	return path, nil
+}
 
-	err := ioutil.WriteFile(filepath, []byte(content), 0644)
+// applyEdits applies the files a diff or search/replace block changes.
+func applyEdits(outputDir string, block CodeBlock, quota *FileQuota) ([]SavedCodeBlock, []RejectedFile) {
+	edits, err := parseEdits(block)
 	if err != nil {
-		return fmt.Errorf("failed to write file %s: %v", filepath, err)
+		return nil, []RejectedFile{{Path: block.Filename(), Reason: err.Error(), Hunk: block.Body}}
 	}
 
-	log.Printf("[EXECUTOR] : Created file: %s\n", filepath)
-	return nil
+	var saved []SavedCodeBlock
+	var rejected []RejectedFile
+	for _, edit := range edits {
+		content, failed, err := applyEdit(outputDir, edit, quota)
+		if err != nil {
+			log.Printf("[EXECUTOR] : Rejected edit of %q: %v\n", edit.Path, err)
+			rejected = append(rejected, RejectedFile{Path: edit.Path, Reason: err.Error()})
+			continue
+		}
+		for _, h := range failed {
+			rejected = append(rejected, RejectedFile{Path: edit.Path, Reason: "the hunk does not match the file", Hunk: h.Text})
+		}
+		if content == nil {
+			log.Printf("[EXECUTOR] : Deleted file: %s\n", edit.Path)
+			continue
+		}
+		log.Printf("[EXECUTOR] : Edited file: %s, %d of %d hunks applied\n", edit.Path, len(edit.Hunks)-len(failed), len(edit.Hunks))
+		path, _ := RelativePath(edit.Path)
+		saved = append(saved, SavedCodeBlock{Path: filepath.ToSlash(path), Size: len(content), Attributes: block.Attributes})
+	}
+	return saved, rejected
 }
 
 func getExtensionForLanguage(language string) string {
 	switch strings.ToLower(language) {
-	case "python":
+	case "python", "python3", "py":
 		return ".py"
-	case "javascript":
+	case "javascript", "js", "node":
 		return ".js"
 	case "java":
 		return ".java"
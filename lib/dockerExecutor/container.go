@@ -0,0 +1,111 @@
+package dockerexecutor
+
+import (
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"context"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+)
+
+// containerVolumeDirectory is where the workspace shows up in containers.
+const containerVolumeDirectory = "/app"
+
+const (
+	// labelTask marks the container of a task with the task's container name.
+	labelTask = "codexec.task"
+	// labelPool marks warm containers with their image.
+	labelPool = "codexec.pool"
+)
+
+// containerSpec is what an idle container is started with, commands are
+// run in it later through exec.
+type containerSpec struct {
+	Name  string
+	Image string
+	// Ref is the reference the container starts from, Image when empty
+	Ref       string
+	Workspace string
+	Limits    codexectypes.Limits
+	Network   pb.NetworkPolicy
+	Egress    Egress
+	Labels    map[string]string
+}
+
+// startContainer creates and starts a container for spec, it returns its id
+// and the network policy actually applied.
+func startContainer(ctx context.Context, cli *client.Client, spec containerSpec) (string, pb.NetworkPolicy, error) {
+	profile := ProfileFor(spec.Image)
+	if err := profile.ownWorkspace(spec.Workspace); err != nil {
+		log.Printf("[EXECUTOR] failed to hand the workspace to %s: %v", profile.User, err)
+	}
+
+	networkMode, env, applied := networkFor(spec.Network, spec.Egress)
+	ref := spec.Ref
+	if ref == "" {
+		ref = spec.Image
+	}
+	containerConfig := &container.Config{
+		Image:  ref,
+		Cmd:    []string{"tail", "-f", "/dev/null"},
+		Env:    env,
+		Labels: spec.Labels,
+	}
+	containerHostConfig := &container.HostConfig{
+		Mounts: []mount.Mount{{
+			Type:   mount.TypeBind,
+			Source: spec.Workspace,
+			Target: containerVolumeDirectory,
+		}},
+		NetworkMode: networkMode,
+		Resources:   resources(spec.Limits),
+		Tmpfs:       tmpfs(spec.Limits),
+	}
+	profile.apply(containerConfig, containerHostConfig)
+
+	log.Printf("[EXECUTOR] - container %s creating", spec.Name)
+	resp, err := cli.ContainerCreate(ctx, containerConfig, containerHostConfig, nil, nil, spec.Name)
+	if err != nil {
+		return "", applied, fmt.Errorf("failed to create container %s: %w", spec.Name, err)
+	}
+
+	log.Printf("[EXECUTOR] - container %s starting", spec.Name)
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		cli.ContainerRemove(context.Background(), resp.ID, container.RemoveOptions{Force: true})
+		return "", applied, fmt.Errorf("failed to start container %s: %w", spec.Name, err)
+	}
+	return resp.ID, applied, nil
+}
+
+// running reports whether the container id is still up.
+func running(ctx context.Context, cli *client.Client, id string) bool {
+	inspect, err := cli.ContainerInspect(ctx, id)
+	return err == nil && inspect.State != nil && inspect.State.Running
+}
+
+// execQuiet runs cmd in the container id, discarding its output, and
+// returns its exit code.
+func execQuiet(ctx context.Context, cli *client.Client, id string, cmd ...string) (int, error) {
+	exec, err := cli.ContainerExecCreate(ctx, id, types.ExecConfig{Cmd: cmd, AttachStdout: true, AttachStderr: true})
+	if err != nil {
+		return 0, err
+	}
+	attached, err := cli.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return 0, err
+	}
+	defer attached.Close()
+	io.Copy(io.Discard, attached.Reader)
+
+	inspect, err := cli.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return 0, err
+	}
+	return inspect.ExitCode, nil
+}
@@ -1,179 +1,305 @@
 package dockerexecutor
 
 import (
-	"bytes"
 	"codexec/lib"
+	"codexec/lib/buildpack"
+	"codexec/lib/executor"
+	pb "codexec/protos/go"
 	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
-	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
 )
 
-type DockerExecuteParams struct {
-	ContainerName    string
-	DockerImage      string
-	WorkingDirectory string
-	Context          context.Context
-	Cancel           context.CancelFunc
+// Docker is the executor backend running the rounds of a task in one
+// container, kept until Teardown and taken from Pool when it has a warm one
+// for the image.
+type Docker struct {
+	// Egress is where containers under the allowlist policy get out, see EnsureNetwork
+	Egress Egress
+	Images *Images
+	Pool   *Pool
+
+	mu    sync.Mutex
+	tasks map[string]*taskContainer
 }
 
-type DockerExecuteResponse struct {
-	ExitCode int
-	Stdout   string
+// taskContainer is the container a task runs its rounds in.
+type taskContainer struct {
+	id      string
+	network pb.NetworkPolicy
+	pooled  *pooledContainer
 }
 
-type ExecutorError struct {
-	Code string
+// NewDocker returns a backend, images may be nil to only use images already
+// present and pool nil to start a new container for every task.
+func NewDocker(egress Egress, images *Images, pool *Pool) *Docker {
+	return &Docker{Egress: egress, Images: images, Pool: pool, tasks: make(map[string]*taskContainer)}
 }
 
-func handlePanic(params DockerExecuteParams) {
-	if r := recover(); r != nil {
-		cli, _ := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
-		ctx := context.Background()
-
-		if error, ok := r.(ExecutorError); ok {
-			switch error.Code {
-			case "docker:container:start", "docker:container:execCreate", "docker:container:execAttach", "docker:container:stdcopy", "docker:container:execInspect", "docker:container:stop":
-				log.Println("[EXECUTOR] error starting container")
-				cli.ContainerRemove(ctx, params.ContainerName, container.RemoveOptions{Force: true})
-				break
-
-			case "docker:container:remove":
-				log.Println("[EXECUTOR] error removing container")
-				cli.ContainerRemove(ctx, params.ContainerName, container.RemoveOptions{})
-				break
-			default:
-				log.Printf("[EXECUTOR] Default %s", error.Code)
-			}
-		}
+// RemoveContainer force removes a container left behind by an interrupted task.
+func RemoveContainer(name string) error {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	err = cli.ContainerRemove(context.Background(), name, container.RemoveOptions{Force: true})
+	if err != nil && !client.IsErrNotFound(err) {
+		return err
 	}
+	return nil
 }
 
-func Run(params DockerExecuteParams) DockerExecuteResponse {
+func (d *Docker) Prepare(ctx context.Context, params executor.Params) (pb.NetworkPolicy, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return pb.NetworkPolicy_NETWORK_UNSET, executor.ExecutorError{Kind: executor.ErrCreate, Code: "docker:client", Err: err}
+	}
+	defer cli.Close()
+
+	if err := os.MkdirAll(params.WorkingDirectory, os.ModePerm); err != nil {
+		return pb.NetworkPolicy_NETWORK_UNSET, executor.ExecutorError{Kind: executor.ErrCreate, Code: "workspace:create", Err: err}
+	}
+
+	task, started, err := d.containerFor(ctx, cli, params)
+	if err != nil {
+		return pb.NetworkPolicy_NETWORK_UNSET, err
+	}
+	// files saved by the server belong to it, the whole workspace when the
+	// task just got its container and the files of the round afterwards
+	profile := ProfileFor(params.DockerImage)
+	if started {
+		err = profile.ownWorkspace(params.WorkingDirectory)
+	} else {
+		err = profile.ownFiles(params.WorkingDirectory, blockPaths(params.Blocks))
+	}
+	if err != nil {
+		log.Printf("[EXECUTOR] failed to hand the workspace to %s: %v", profile.User, err)
+	}
+	return task.network, nil
+}
+
+func (d *Docker) Exec(ctx context.Context, params executor.Params, command string, stdout io.Writer, stderr io.Writer) (int, error) {
+	d.mu.Lock()
+	task := d.tasks[params.ContainerName]
+	d.mu.Unlock()
+	if task == nil {
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "docker:container:missing", Err: fmt.Errorf("no container for %s", params.ContainerName)}
+	}
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return 0, executor.ExecutorError{Kind: executor.ErrCreate, Code: "docker:client", Err: err}
+	}
+	defer cli.Close()
+
+	pidFile := fmt.Sprintf("/tmp/.codexec-%d.pid", time.Now().UnixNano())
+	execConfig := types.ExecConfig{
+		Cmd:          []string{"/bin/sh", "-c", trackedCommand, pidFile, command},
+		Env:          buildpack.Env(params.Buildpack),
+		AttachStdout: true,
+		AttachStderr: true,
+	}
 
-	defer handlePanic(params)
+	// Create the exec instance
+	log.Printf("[EXECUTOR] - container exec ")
+	execID, err := cli.ContainerExecCreate(ctx, task.id, execConfig)
+	if err != nil {
+		d.discard(params)
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "docker:container:execCreate", Err: err}
+	}
 
-	log.Println("[EXECUTOR] - spawning docker-container")
-	var executeResponse DockerExecuteResponse
-	ctx := params.Context
+	// Start the exec instance
+	log.Printf("[EXECUTOR] - container exec attach ")
+	execResp, err := cli.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
+	if err != nil {
+		d.discard(params)
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "docker:container:execAttach", Err: err}
+	}
+	defer execResp.Close()
+
+	copied := make(chan error, 1)
+	go func() {
+		// the exec stream multiplexes stdout and stderr in framed chunks
+		_, err := stdcopy.StdCopy(stdout, stderr, execResp.Reader)
+		copied <- err
+	}()
 
 	select {
+	case err = <-copied:
 	case <-ctx.Done():
-		log.Println("Got cancel request")
-		executeResponse.ExitCode = 0
-		executeResponse.Stdout = "canceled"
-		return executeResponse
+		d.stop(cli, params, task.id, pidFile, copied)
+		execResp.Close()
+		return 0, ctx.Err()
+	}
+	if err != nil {
+		d.discard(params)
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "docker:container:stdcopy", Err: err}
+	}
+
+	// Inspect the exec instance to get the exit code
+	inspectResp, err := cli.ContainerExecInspect(ctx, execID.ID)
+	if err != nil {
+		d.discard(params)
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "docker:container:execInspect", Err: err}
+	}
+	return inspectResp.ExitCode, nil
+}
 
-	default:
-		cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// trackedCommand runs the command given as $1 and keeps the pid of the
+// shell running it in the file $0 meanwhile, for killTree.
+const trackedCommand = `echo $$ > "$0" 2>/dev/null; /bin/sh -c "$1"; status=$?; rm -f "$0"; exit $status`
+
+// killTree kills the process whose pid is in the file $0 and all its
+// descendants, each is stopped first so it cannot fork while they are
+// gathered.
+const killTree = `kill_tree() { kill -STOP "$1" 2>/dev/null; for child in $(cat /proc/"$1"/task/*/children 2>/dev/null); do kill_tree "$child"; done; kill -9 "$1" 2>/dev/null; }; [ -s "$0" ] && kill_tree "$(cat "$0")"; rm -f "$0"`
+
+// killGrace is how long a killed command has to end its exec stream before
+// a harder kill is tried.
+const killGrace = 2 * time.Second
+
+// stop kills a command that ran past its timeout or was cancelled and waits
+// for its output to end. Its own processes go first, then every process of
+// the task in the container, which both keep the container and its files
+// for the next round. Only when that fails is the container killed, it is
+// then dropped and never returned to the pool.
+func (d *Docker) stop(cli *client.Client, params executor.Params, id string, pidFile string, copied <-chan error) {
+	kills := []struct {
+		what string
+		cmd  []string
+	}{
+		{"the command", []string{"/bin/sh", "-c", killTree, pidFile}},
+		{"all processes", []string{"/bin/sh", "-c", killAll}},
+	}
+	for _, kill := range kills {
+		log.Printf("[EXECUTOR] - killing %s in the container of %s", kill.what, params.ContainerName)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := execQuiet(ctx, cli, id, kill.cmd...)
+		cancel()
 		if err != nil {
-			log.Printf("Failed to create Docker client: %v", err)
+			log.Printf("[EXECUTOR] failed to kill the command of %s: %v", params.ContainerName, err)
+			break
 		}
-
-		containerVolumeDirectory := "/app"
-
-		if err := os.MkdirAll(params.WorkingDirectory, os.ModePerm); err != nil {
-			log.Fatal(err)
+		select {
+		case <-copied:
+			return
+		case <-time.After(killGrace):
 		}
+	}
 
-		containerConfig := &container.Config{
-			Image: params.DockerImage,
-			Cmd:   []string{"tail", "-f", "/dev/null"},
-		}
+	log.Printf("[EXECUTOR] - killing container of %s", params.ContainerName)
+	if err := cli.ContainerKill(context.Background(), id, "KILL"); err != nil {
+		log.Printf("[EXECUTOR] failed to kill container %s: %v", params.ContainerName, err)
+	}
+	d.discard(params)
+}
 
-		containerHostConfig := &container.HostConfig{
-			Mounts: []mount.Mount{{
-				Type:   mount.TypeBind,
-				Source: params.WorkingDirectory,
-				Target: containerVolumeDirectory,
-			},
-			},
-		}
+// Teardown removes the container of a finished task, a warm one goes back
+// to the pool and leaves the task files in the workspace.
+func (d *Docker) Teardown(params executor.Params) {
+	d.mu.Lock()
+	task := d.tasks[params.ContainerName]
+	delete(d.tasks, params.ContainerName)
+	d.mu.Unlock()
+	if task == nil {
+		return
+	}
 
-		log.Printf("[EXECUTOR] - container creating")
-		resp, err := cli.ContainerCreate(ctx, containerConfig, containerHostConfig, nil, nil, params.ContainerName)
-		if err != nil {
-			panic(ExecutorError{Code: "docker:container:create"})
-		}
+	if task.pooled != nil {
+		d.Pool.release(task.pooled, params.WorkingDirectory)
+		return
+	}
+	log.Printf("[EXECUTOR] - container %s remove", params.ContainerName)
+	if err := RemoveContainer(task.id); err != nil {
+		log.Printf("[EXECUTOR] failed to remove container of %s: %v", params.ContainerName, err)
+	}
+}
 
-		log.Printf("[EXECUTOR] - container starting")
-		if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
-			panic(ExecutorError{Code: "docker:container:start"})
-		}
+func blockPaths(blocks []lib.SavedCodeBlock) []string {
+	paths := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		paths = append(paths, block.Path)
+	}
+	return paths
+}
 
-		commands := lib.GenerateCommands(params.WorkingDirectory)
-
-		logFileName := filepath.Join(params.WorkingDirectory, fmt.Sprintf("%s_output.log", params.ContainerName))
-		logFile, err := os.Create(logFileName)
-
-		for _, cmd := range commands {
-
-			log.Printf("[EXECUTOR] - running docker cmd = %s", cmd)
-			execConfig := types.ExecConfig{
-				Cmd:          []string{"/bin/sh", "-c", cmd},
-				AttachStdout: true,
-				AttachStderr: true,
-			}
-
-			// Create the exec instance
-			log.Printf("[EXECUTOR] - container exec ")
-			execID, err := cli.ContainerExecCreate(ctx, resp.ID, execConfig)
-			if err != nil {
-				panic(ExecutorError{Code: "docker:container:execCreate"})
-			}
-
-			// Start the exec instance
-			log.Printf("[EXECUTOR] - container exec attach ")
-			execResp, err := cli.ContainerExecAttach(ctx, execID.ID, types.ExecStartCheck{})
-			if err != nil {
-				panic(ExecutorError{Code: "docker:container:execAttach"})
-			}
-
-			var buf bytes.Buffer
-			_, err = io.Copy(&buf, execResp.Reader)
-
-			defer execResp.Close()
-
-			_, err = stdcopy.StdCopy(logFile, logFile, execResp.Reader)
-			if err != nil {
-				panic(ExecutorError{Code: "docker:container:stdcopy"})
-			}
-
-			// Inspect the exec instance to get the exit code
-			inspectResp, err := cli.ContainerExecInspect(ctx, execID.ID)
-			if err != nil {
-				panic(ExecutorError{Code: "docker:container:execInspect"})
-			}
-
-			executeResponse.ExitCode = inspectResp.ExitCode
-			executeResponse.Stdout = buf.String()
-
-			if inspectResp.ExitCode != 0 {
-				log.Printf("Command '%s' exited with status code: %d (log: %s)\n", cmd, inspectResp.ExitCode, logFileName)
-				break
-			}
+// containerFor returns the running container of the task, starting one or
+// taking a warm one from the pool on its first round, started tells it was.
+func (d *Docker) containerFor(ctx context.Context, cli *client.Client, params executor.Params) (*taskContainer, bool, error) {
+	d.mu.Lock()
+	task := d.tasks[params.ContainerName]
+	d.mu.Unlock()
+	if task != nil {
+		if running(ctx, cli, task.id) {
+			return task, false, nil
 		}
+		log.Printf("[EXECUTOR] container of %s is gone, starting a new one", params.ContainerName)
+		d.discard(params)
+		task = nil
+	}
 
-		log.Printf("[EXECUTOR] - container stop ")
-		err = cli.ContainerStop(ctx, params.ContainerName, container.StopOptions{})
+	if d.Pool != nil {
+		if pooled := d.Pool.acquire(ctx, cli, params); pooled != nil {
+			task = &taskContainer{id: pooled.id, network: pooled.network, pooled: pooled}
+		}
+	}
+	if task == nil {
+		ref, err := d.Images.Ensure(ctx, cli, params.DockerImage, params.Events)
 		if err != nil {
-			panic(ExecutorError{Code: "docker:container:stop"})
+			return nil, false, err
+		}
+		id, applied, err := startContainer(ctx, cli, containerSpec{
+			Name:      params.ContainerName,
+			Image:     params.DockerImage,
+			Ref:       ref,
+			Workspace: params.WorkingDirectory,
+			Limits:    params.Limits,
+			Network:   params.Network,
+			Egress:    d.Egress,
+			Labels:    map[string]string{labelTask: params.ContainerName},
+		})
+		if client.IsErrNotFound(err) {
+			return nil, false, executor.ExecutorError{Kind: executor.ErrImageNotFound, Code: "docker:container:create", Err: err}
 		}
-
-		log.Printf("[EXECUTOR] - container remove ")
-		err = cli.ContainerRemove(ctx, params.ContainerName, container.RemoveOptions{})
 		if err != nil {
-			panic(ExecutorError{Code: "docker:container:remove"})
+			return nil, false, executor.ExecutorError{Kind: executor.ErrCreate, Code: "docker:container:create", Err: err}
 		}
-		return executeResponse
+		task = &taskContainer{id: id, network: applied}
 	}
 
+	d.mu.Lock()
+	d.tasks[params.ContainerName] = task
+	d.mu.Unlock()
+	return task, true, nil
+}
+
+// discard drops the container of a task that can not be used anymore, the
+// next round starts another one.
+func (d *Docker) discard(params executor.Params) {
+	d.mu.Lock()
+	task := d.tasks[params.ContainerName]
+	delete(d.tasks, params.ContainerName)
+	d.mu.Unlock()
+	if task == nil {
+		return
+	}
+
+	if task.pooled != nil {
+		d.Pool.retire(task.pooled)
+		return
+	}
+	if err := RemoveContainer(task.id); err != nil {
+		log.Printf("[EXECUTOR] failed to remove container of %s: %v", params.ContainerName, err)
+	}
 }
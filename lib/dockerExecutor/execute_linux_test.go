@@ -0,0 +1,77 @@
+package dockerexecutor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestKillTreeKillsOnlyTheCommand runs the scripts of Exec in the shell of
+// the host, containers run them the same way.
+func TestKillTreeKillsOnlyTheCommand(t *testing.T) {
+	dir := t.TempDir()
+	start := func(name string) (*exec.Cmd, chan error, string) {
+		pidFile := filepath.Join(dir, name+".pid")
+		cmd := exec.Command("/bin/sh", "-c", trackedCommand, pidFile, "sleep 30 & sleep 30")
+		if err := cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		return cmd, done, pidFile
+	}
+	killed, killedDone, pidFile := start("killed")
+	other, otherDone, _ := start("other")
+	defer other.Process.Kill()
+	time.Sleep(200 * time.Millisecond)
+	spawned := descendants(killed.Process.Pid)
+	if len(spawned) != 3 {
+		t.Fatalf("the command runs %v, want the shell and its two sleeps", spawned)
+	}
+
+	if err := exec.Command("/bin/sh", "-c", killTree, pidFile).Run(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-killedDone:
+	case <-time.After(5 * time.Second):
+		killed.Process.Kill()
+		t.Fatal("the command was not killed")
+	}
+	for _, pid := range spawned {
+		if alive(pid) {
+			t.Errorf("process %s of the command is still running", pid)
+		}
+	}
+	select {
+	case err := <-otherDone:
+		t.Fatalf("another command was killed too: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func descendants(pid int) []string {
+	var pids []string
+	children, _ := os.ReadFile(fmt.Sprintf("/proc/%d/task/%d/children", pid, pid))
+	for _, child := range strings.Fields(string(children)) {
+		pids = append(pids, child)
+		id, _ := strconv.Atoi(child)
+		pids = append(pids, descendants(id)...)
+	}
+	return pids
+}
+
+// alive tells whether pid runs, a killed process waiting to be reaped does not.
+func alive(pid string) bool {
+	stat, err := os.ReadFile("/proc/" + pid + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
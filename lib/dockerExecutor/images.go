@@ -0,0 +1,214 @@
+package dockerexecutor
+
+import (
+	"codexec/lib"
+	"codexec/lib/events"
+	"codexec/lib/executor"
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+// buildpackPrefix names the images built from a buildpack directory,
+// code.buildpack.python is built from `<Buildpacks>/python`.
+const buildpackPrefix = "code.buildpack."
+
+// Images makes sure the image of a container is there before it starts,
+// building it from its buildpack or pulling it.
+type Images struct {
+	// Buildpacks holds a directory with a Dockerfile per buildpack
+	Buildpacks string
+	// Mirror is the registry missing images are pulled from, docker hub
+	// when empty
+	Mirror string
+	// Pull allows pulling missing images at all
+	Pull bool
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewImages(buildpacks string, mirror string, pull bool) *Images {
+	return &Images{Buildpacks: buildpacks, Mirror: mirror, Pull: pull, locks: make(map[string]*sync.Mutex)}
+}
+
+// Ensure returns the reference a container of image starts from, building
+// or pulling it first when it is missing. Buildpack images are tagged with
+// the hash of their directory so a changed buildpack is built again. The
+// output of a build or pull goes to emitter, which may be nil.
+func (i *Images) Ensure(ctx context.Context, cli *client.Client, image string, emitter codexectypes.EventEmitter) (string, error) {
+	if i == nil {
+		return image, nil
+	}
+
+	ref := image
+	dir, hash, err := i.buildpack(image)
+	if err != nil {
+		return "", executor.ExecutorError{Kind: executor.ErrCreate, Code: "docker:image:build", Err: err}
+	}
+	if dir != "" {
+		ref = image + ":" + hash[:12]
+	}
+
+	// tasks asking for the same missing image wait for one build or pull
+	lock := i.lock(ref)
+	lock.Lock()
+	defer lock.Unlock()
+
+	_, _, err = cli.ImageInspectWithRaw(ctx, ref)
+	if err == nil {
+		return ref, nil
+	}
+	if !client.IsErrNotFound(err) {
+		return "", executor.ExecutorError{Kind: executor.ErrCreate, Code: "docker:image:inspect", Err: err}
+	}
+
+	if dir != "" {
+		if err := i.build(ctx, cli, image, ref, dir, emitter); err != nil {
+			return "", executor.ExecutorError{Kind: executor.ErrCreate, Code: "docker:image:build", Err: err}
+		}
+		return ref, nil
+	}
+	if !i.Pull {
+		return "", executor.ExecutorError{Kind: executor.ErrImageNotFound, Code: "docker:image:missing", Err: fmt.Errorf("%s is not present and pulling is off", image)}
+	}
+	if err := i.pull(ctx, cli, image, emitter); err != nil {
+		kind := executor.ErrCreate
+		if client.IsErrNotFound(err) || notFound(err) {
+			kind = executor.ErrImageNotFound
+		}
+		return "", executor.ExecutorError{Kind: kind, Code: "docker:image:pull", Err: err}
+	}
+	return ref, nil
+}
+
+func (i *Images) lock(ref string) *sync.Mutex {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	lock, ok := i.locks[ref]
+	if !ok {
+		lock = &sync.Mutex{}
+		i.locks[ref] = lock
+	}
+	return lock
+}
+
+// buildpack returns the directory image is built from and its hash, an
+// empty directory when image is not an untagged buildpack image.
+func (i *Images) buildpack(image string) (string, string, error) {
+	name, ok := strings.CutPrefix(image, buildpackPrefix)
+	if !ok || i.Buildpacks == "" || name == "" || strings.ContainsAny(name, ":/@") {
+		return "", "", nil
+	}
+	dir := filepath.Join(i.Buildpacks, name)
+	if _, err := os.Stat(filepath.Join(dir, "Dockerfile")); err != nil {
+		return "", "", nil
+	}
+	hash, err := lib.HashDirectory(dir)
+	if err != nil {
+		return "", "", err
+	}
+	return dir, hash, nil
+}
+
+// build builds ref from dir, it is tagged as image too so the plain name
+// points at the latest build.
+func (i *Images) build(ctx context.Context, cli *client.Client, image string, ref string, dir string, emitter codexectypes.EventEmitter) error {
+	log.Printf("[IMAGES] building %s from %s", ref, dir)
+	buildContext, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(lib.WriteTarGz(writer, dir))
+	}()
+	defer buildContext.Close()
+
+	response, err := cli.ImageBuild(ctx, buildContext, types.ImageBuildOptions{
+		Tags:        []string{ref, image},
+		Remove:      true,
+		ForceRemove: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	return follow(response.Body, image, pb.ImageAction_IMAGE_BUILD, emitter)
+}
+
+// pull fetches image, from the mirror when there is one.
+func (i *Images) pull(ctx context.Context, cli *client.Client, name string, emitter codexectypes.EventEmitter) error {
+	source := name
+	if i.Mirror != "" && !qualified(name) {
+		source = strings.TrimSuffix(i.Mirror, "/") + "/" + name
+	}
+	log.Printf("[IMAGES] pulling %s", source)
+	body, err := cli.ImagePull(ctx, source, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := follow(body, name, pb.ImageAction_IMAGE_PULL, emitter); err != nil {
+		return err
+	}
+	if source != name {
+		return cli.ImageTag(ctx, source, name)
+	}
+	return nil
+}
+
+// follow reads the messages of a build or pull, forwarding their lines
+// without the download progress to emitter.
+func follow(body io.Reader, image string, action pb.ImageAction, emitter codexectypes.EventEmitter) error {
+	decoder := json.NewDecoder(body)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if message.Error != nil {
+			return message.Error
+		}
+		if message.Progress != nil && message.Progress.Total > 0 {
+			continue
+		}
+
+		line := message.Stream
+		if line == "" && message.Status != "" {
+			line = message.Status
+			if message.ID != "" {
+				line = message.ID + ": " + line
+			}
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" || emitter == nil {
+			continue
+		}
+		emitter.Emit(events.ImageProgress(image, action, line))
+	}
+}
+
+// qualified reports whether image names its registry, e.g. ghcr.io/org/image.
+func qualified(image string) bool {
+	host, _, ok := strings.Cut(image, "/")
+	return ok && (strings.ContainsAny(host, ".:") || host == "localhost")
+}
+
+// notFound reports whether a registry answered that the image does not
+// exist, the daemon only passes its message along.
+func notFound(err error) bool {
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "not found") || strings.Contains(message, "manifest unknown") || strings.Contains(message, "does not exist")
+}
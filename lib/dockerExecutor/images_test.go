@@ -0,0 +1,68 @@
+package dockerexecutor
+
+import (
+	pb "codexec/protos/go"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []*pb.CodeResponse
+}
+
+func (r *recorder) Emit(event *pb.CodeResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestFollowForwardsLinesAndFails(t *testing.T) {
+	body := `{"stream":"Step 1/3 : FROM python:3.11-alpine\n"}
+{"status":"Downloading","progressDetail":{"current":10,"total":100},"id":"a1b2"}
+{"status":"Pull complete","progressDetail":{},"id":"a1b2"}
+{"errorDetail":{"message":"COPY failed"},"error":"COPY failed"}
+{"stream":"never read\n"}
+`
+	recorder := &recorder{}
+	err := follow(strings.NewReader(body), "code.buildpack.python", pb.ImageAction_IMAGE_BUILD, recorder)
+	if err == nil || err.Error() != "COPY failed" {
+		t.Fatalf("expected the build error, got %v", err)
+	}
+
+	var lines []string
+	for _, event := range recorder.events {
+		lines = append(lines, event.GetImage().Line)
+	}
+	want := []string{"Step 1/3 : FROM python:3.11-alpine", "a1b2: Pull complete"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("lines = %q, want %q", lines, want)
+	}
+}
+
+// TestBuildpackTagFollowsContent checks that a buildpack image is tagged
+// with the hash of its directory.
+func TestBuildpackTagFollowsContent(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "python"), os.ModePerm)
+	os.WriteFile(filepath.Join(dir, "python", "Dockerfile"), []byte("FROM python:3.11-alpine\n"), 0644)
+	images := NewImages(dir, "", true)
+
+	buildpack, first, err := images.buildpack("code.buildpack.python")
+	if err != nil || buildpack != filepath.Join(dir, "python") {
+		t.Fatalf("buildpack = %q, %v", buildpack, err)
+	}
+	os.WriteFile(filepath.Join(dir, "python", "requirements.txt"), []byte("requests\n"), 0644)
+	if _, second, _ := images.buildpack("code.buildpack.python"); second == first {
+		t.Error("hash did not change with the buildpack")
+	}
+
+	for _, image := range []string{"code.buildpack.python:3", "code.buildpack.ruby", "python:3.11"} {
+		if buildpack, _, _ := images.buildpack(image); buildpack != "" {
+			t.Errorf("%s built from %s", image, buildpack)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package dockerexecutor
+
+import (
+	codexectypes "codexec/types"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// resources translates the limits of a task into container resources.
+func resources(limits codexectypes.Limits) container.Resources {
+	r := container.Resources{
+		NanoCPUs:  int64(limits.CPUs * 1e9),
+		CPUShares: limits.CPUShares,
+		Memory:    limits.MemoryMB << 20,
+	}
+	if r.Memory > 0 {
+		// no swap on top of the memory limit
+		r.MemorySwap = r.Memory
+	}
+	if limits.Pids > 0 {
+		pids := limits.Pids
+		r.PidsLimit = &pids
+	}
+	return r
+}
+
+// tmpfsOptions mount /tmp executable, HOME is there under a read-only root
+// so whatever code builds or installs below it has to run from it. Docker
+// mounts a tmpfs noexec unless told otherwise.
+const tmpfsOptions = "rw,exec,nosuid,nodev"
+
+// tmpfs mounts a size limited /tmp, the workspace mount is the only other
+// place code is expected to write to.
+func tmpfs(limits codexectypes.Limits) map[string]string {
+	if limits.TmpfsMB <= 0 {
+		return nil
+	}
+	return map[string]string{"/tmp": fmt.Sprintf("%s,size=%dm", tmpfsOptions, limits.TmpfsMB)}
+}
@@ -0,0 +1,77 @@
+package dockerexecutor
+
+import (
+	pb "codexec/protos/go"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// Egress is the way out of containers under the allowlist policy, an
+// internal network whose only reachable peer is the egress proxy. Gateway
+// is the host address on that network, anything listening on it is
+// reachable from the containers.
+type Egress struct {
+	Network  string
+	ProxyURL string
+	Gateway  string
+}
+
+// networkFor returns the network mode and environment of a container under
+// policy. Without an egress network the allowlist policy falls back to no
+// network at all, the returned policy is the one actually applied.
+func networkFor(policy pb.NetworkPolicy, egress Egress) (container.NetworkMode, []string, pb.NetworkPolicy) {
+	switch policy {
+	case pb.NetworkPolicy_NETWORK_FULL:
+		return network.NetworkDefault, nil, policy
+	case pb.NetworkPolicy_NETWORK_ALLOWLIST:
+		if egress.Network != "" {
+			env := []string{
+				"HTTP_PROXY=" + egress.ProxyURL,
+				"HTTPS_PROXY=" + egress.ProxyURL,
+				"http_proxy=" + egress.ProxyURL,
+				"https_proxy=" + egress.ProxyURL,
+			}
+			return container.NetworkMode(egress.Network), env, policy
+		}
+		log.Printf("[EXECUTOR] no egress network configured, running without network")
+	}
+	return network.NetworkNone, nil, pb.NetworkPolicy_NETWORK_NONE
+}
+
+// EnsureNetwork creates the internal network name unless it exists and
+// returns its gateway, the host address containers on it can reach.
+func EnsureNetwork(name string) (string, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+	ctx := context.Background()
+
+	inspect, err := cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	if client.IsErrNotFound(err) {
+		log.Printf("[EXECUTOR] creating internal network %s", name)
+		if _, err := cli.NetworkCreate(ctx, name, network.CreateOptions{Driver: "bridge", Internal: true}); err != nil {
+			return "", err
+		}
+		inspect, err = cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !inspect.Internal {
+		return "", fmt.Errorf("network %s is not internal, containers on it could bypass the proxy", name)
+	}
+	for _, ipam := range inspect.IPAM.Config {
+		if ipam.Gateway != "" {
+			return ipam.Gateway, nil
+		}
+	}
+	return "", fmt.Errorf("network %s has no gateway", name)
+}
@@ -0,0 +1,360 @@
+package dockerexecutor
+
+import (
+	"codexec/lib/executor"
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+// cleanIdle is run in a warm container after each task, so the next one
+// does not see leftover processes or files in /tmp. /app is the empty slot
+// by then, see Pool.release.
+const cleanIdle = killAll + "; rm -rf /tmp/* /tmp/.[!.]* 2>/dev/null; true"
+
+// killAll kills every process the container user may signal but the shell
+// running it and the one the container runs, pid 1.
+const killAll = "kill -9 -1 2>/dev/null; true"
+
+// Pool keeps idle containers started ahead of time for some images, so the
+// first round of a task does not wait for one.
+//
+// A warm container mounts an empty slot directory at /app. While a task
+// uses it the slot takes the place of the task workspace, bind mounts
+// follow a directory when it is renamed, and on release the files move back
+// into a plain workspace directory. Warm containers run with the default
+// limits and network policy, tasks asking for another network or tmpfs
+// size get a container of their own.
+type Pool struct {
+	// Sizes is how many idle containers to keep per image
+	Sizes map[string]int
+	// MaxUses is how many tasks a container serves before it is replaced
+	MaxUses int
+	// Dir holds the slot directories, it must be on the filesystem of the workspaces
+	Dir     string
+	Limits  codexectypes.Limits
+	Network pb.NetworkPolicy
+	Egress  Egress
+	// Images builds or pulls a missing image before its containers start
+	Images *Images
+
+	mu      sync.Mutex
+	idle    map[string][]*pooledContainer
+	filling sync.Mutex
+}
+
+type pooledContainer struct {
+	id      string
+	name    string
+	image   string
+	slot    string
+	network pb.NetworkPolicy
+	uses    int
+	// adopted is set while the slot stands in for a task workspace
+	adopted bool
+}
+
+// Start removes warm containers of an earlier run, fills the pool and
+// checks its idle containers every interval.
+func (p *Pool) Start(interval time.Duration) error {
+	p.idle = make(map[string][]*pooledContainer)
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	leftovers, err := cli.ContainerList(context.Background(), container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", labelPool)),
+	})
+	if err != nil {
+		return err
+	}
+	for _, leftover := range leftovers {
+		RemoveContainer(leftover.ID)
+	}
+	if err := os.RemoveAll(p.Dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(p.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	go func() {
+		p.fill()
+		for range time.Tick(interval) {
+			p.check()
+			p.fill()
+		}
+	}()
+	return nil
+}
+
+// fill starts containers until every image has its idle ones.
+func (p *Pool) fill() {
+	p.filling.Lock()
+	defer p.filling.Unlock()
+
+	for image, size := range p.Sizes {
+		p.mu.Lock()
+		missing := size - len(p.idle[image])
+		p.mu.Unlock()
+
+		for i := 0; i < missing; i++ {
+			pooled, err := p.start(image)
+			if err != nil {
+				log.Printf("[POOL] failed to start a container for %s: %v", image, err)
+				break
+			}
+			p.mu.Lock()
+			p.idle[image] = append(p.idle[image], pooled)
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *Pool) start(image string) (*pooledContainer, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	ref, err := p.Images.Ensure(context.Background(), cli, image, nil)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("codexec-pool-%d", time.Now().UnixNano())
+	slot := filepath.Join(p.Dir, name)
+	if err := os.MkdirAll(slot, os.ModePerm); err != nil {
+		return nil, err
+	}
+	id, applied, err := startContainer(context.Background(), cli, containerSpec{
+		Name:      name,
+		Image:     image,
+		Ref:       ref,
+		Workspace: slot,
+		Limits:    p.Limits,
+		Network:   p.Network,
+		Egress:    p.Egress,
+		Labels:    map[string]string{labelPool: image},
+	})
+	if err != nil {
+		os.RemoveAll(slot)
+		return nil, err
+	}
+	log.Printf("[POOL] %s warm for %s", name, image)
+	return &pooledContainer{id: id, name: name, image: image, slot: slot, network: applied}, nil
+}
+
+// check replaces idle containers that stopped or no longer run commands.
+func (p *Pool) check() {
+	p.mu.Lock()
+	var checking []*pooledContainer
+	for image, idle := range p.idle {
+		checking = append(checking, idle...)
+		delete(p.idle, image)
+	}
+	p.mu.Unlock()
+
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		log.Printf("[POOL] health check skipped: %v", err)
+	}
+	for _, pooled := range checking {
+		if err == nil && !p.healthy(cli, pooled) {
+			log.Printf("[POOL] %s is unhealthy, replacing it", pooled.name)
+			p.retire(pooled)
+			continue
+		}
+		p.mu.Lock()
+		p.idle[pooled.image] = append(p.idle[pooled.image], pooled)
+		p.mu.Unlock()
+	}
+	if cli != nil {
+		cli.Close()
+	}
+}
+
+func (p *Pool) healthy(cli *client.Client, pooled *pooledContainer) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if !running(ctx, cli, pooled.id) {
+		return false
+	}
+	exitCode, err := execQuiet(ctx, cli, pooled.id, "true")
+	return err == nil && exitCode == 0
+}
+
+// acquire hands an idle container fit for params to its task, nil when
+// there is none.
+func (p *Pool) acquire(ctx context.Context, cli *client.Client, params executor.Params) *pooledContainer {
+	if params.Network != p.Network || params.Limits.TmpfsMB != p.Limits.TmpfsMB {
+		return nil
+	}
+	p.mu.Lock()
+	idle := p.idle[params.DockerImage]
+	if len(idle) == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	pooled := idle[len(idle)-1]
+	p.idle[params.DockerImage] = idle[:len(idle)-1]
+	p.mu.Unlock()
+	go p.fill()
+
+	if err := adopt(pooled.slot, params.WorkingDirectory); err != nil {
+		log.Printf("[POOL] failed to move the workspace of %s into %s: %v", params.ContainerName, pooled.name, err)
+		p.retire(pooled)
+		return nil
+	}
+	pooled.adopted = true
+
+	if _, err := cli.ContainerUpdate(ctx, pooled.id, container.UpdateConfig{Resources: resources(params.Limits)}); err != nil {
+		log.Printf("[POOL] failed to apply the limits of %s to %s: %v", params.ContainerName, pooled.name, err)
+		p.release(pooled, params.WorkingDirectory)
+		return nil
+	}
+	log.Printf("[POOL] %s runs %s", pooled.name, params.ContainerName)
+	return pooled
+}
+
+// release takes a container back from the task whose workspace it had,
+// it is retired instead once it served MaxUses tasks or cannot be reset.
+// What the task left running goes before its files move out, a process
+// still writing to /app would otherwise write into the slot of the next
+// task.
+func (p *Pool) release(pooled *pooledContainer, workspace string) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		p.retire(pooled)
+		return
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := execQuiet(ctx, cli, pooled.id, "sh", "-c", killAll); err != nil {
+		log.Printf("[POOL] failed to stop the processes of %s in %s: %v", workspace, pooled.name, err)
+		p.retire(pooled)
+		return
+	}
+	if err := handBack(workspace, pooled.slot); err != nil {
+		log.Printf("[POOL] failed to move the files of %s out of %s: %v", workspace, pooled.name, err)
+		if errors.Is(err, errLeftovers) {
+			// the task files are out, what is left goes with the slot
+			pooled.adopted = false
+		}
+		p.retire(pooled)
+		return
+	}
+	pooled.adopted = false
+	pooled.uses++
+	if p.MaxUses > 0 && pooled.uses >= p.MaxUses {
+		log.Printf("[POOL] %s served %d tasks, recycling it", pooled.name, pooled.uses)
+		p.retire(pooled)
+		return
+	}
+
+	if _, err := execQuiet(ctx, cli, pooled.id, "sh", "-c", cleanIdle); err != nil {
+		log.Printf("[POOL] failed to clean %s: %v", pooled.name, err)
+		p.retire(pooled)
+		return
+	}
+	if _, err := cli.ContainerUpdate(ctx, pooled.id, container.UpdateConfig{Resources: resources(p.Limits)}); err != nil {
+		log.Printf("[POOL] failed to reset the limits of %s: %v", pooled.name, err)
+		p.retire(pooled)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.idle[pooled.image]) >= p.Sizes[pooled.image] {
+		go p.retire(pooled)
+		return
+	}
+	p.idle[pooled.image] = append(p.idle[pooled.image], pooled)
+}
+
+// retire removes a container for good, its slot too unless it is a task
+// workspace right now, and starts a replacement.
+func (p *Pool) retire(pooled *pooledContainer) {
+	if err := RemoveContainer(pooled.id); err != nil {
+		log.Printf("[POOL] failed to remove %s: %v", pooled.name, err)
+	}
+	if !pooled.adopted {
+		os.RemoveAll(pooled.slot)
+	}
+	go p.fill()
+}
+
+// adopt makes slot the workspace, the files already in the workspace move
+// into slot which then takes its place.
+func adopt(slot string, workspace string) error {
+	if err := os.MkdirAll(workspace, os.ModePerm); err != nil {
+		return err
+	}
+	if err := moveEntries(workspace, slot); err != nil {
+		moveEntries(slot, workspace)
+		return err
+	}
+	if err := os.Remove(workspace); err != nil {
+		moveEntries(slot, workspace)
+		return err
+	}
+	return os.Rename(slot, workspace)
+}
+
+// errLeftovers is returned by handBack when files showed up in the slot
+// while the task files moved out of it.
+var errLeftovers = errors.New("files left in the slot")
+
+// handBack undoes adopt, the directory at workspace becomes the slot again
+// and the task files move to a new directory at workspace. The slot has to
+// be empty afterwards, or the next task would find what was written to it
+// meanwhile.
+func handBack(workspace string, slot string) error {
+	if err := os.Rename(workspace, slot); err != nil {
+		return err
+	}
+	if err := os.Mkdir(workspace, os.ModePerm); err != nil {
+		return err
+	}
+	if err := moveEntries(slot, workspace); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(slot)
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: %s", errLeftovers, entries[0].Name())
+	}
+	return nil
+}
+
+func moveEntries(from string, to string) error {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.Rename(filepath.Join(from, entry.Name()), filepath.Join(to, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package dockerexecutor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestAdoptAndHandBack checks that the slot directory, the one a warm
+// container has mounted, carries the task files while adopted and is empty
+// again afterwards.
+func TestAdoptAndHandBack(t *testing.T) {
+	dir := t.TempDir()
+	slot := filepath.Join(dir, "codexec-pool-1")
+	workspace := filepath.Join(dir, "codexec-42")
+	os.Mkdir(slot, os.ModePerm)
+	os.MkdirAll(filepath.Join(workspace, "data"), os.ModePerm)
+	os.WriteFile(filepath.Join(workspace, "codeblock_1.sh"), []byte("echo hi\n"), 0644)
+	mounted, _ := os.Stat(slot)
+
+	if err := adopt(slot, workspace); err != nil {
+		t.Fatal(err)
+	}
+	adopted, err := os.Stat(workspace)
+	if err != nil || !os.SameFile(mounted, adopted) {
+		t.Fatalf("workspace is not the slot directory after adopt: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "codeblock_1.sh")); err != nil {
+		t.Errorf("task file missing after adopt: %v", err)
+	}
+	os.WriteFile(filepath.Join(workspace, "result.txt"), []byte("42\n"), 0644)
+
+	if err := handBack(workspace, slot); err != nil {
+		t.Fatal(err)
+	}
+	back, _ := os.Stat(slot)
+	if !os.SameFile(mounted, back) {
+		t.Error("slot is not the mounted directory after hand back")
+	}
+	if entries, _ := os.ReadDir(slot); len(entries) != 0 {
+		t.Errorf("slot still holds %d entries", len(entries))
+	}
+	for _, name := range []string{"codeblock_1.sh", "result.txt", "data"} {
+		if _, err := os.Stat(filepath.Join(workspace, name)); err != nil {
+			t.Errorf("%s missing from the workspace: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+//go:build unix
+
+package dockerexecutor
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHandBackWithAProcessLeftBehind checks that a process the task left
+// writing to /app does not leave files in the slot of the next task. It
+// writes through its working directory, as a container does through the
+// bind mount, so it follows the slot directory and not the workspace path.
+func TestHandBackWithAProcessLeftBehind(t *testing.T) {
+	for _, killed := range []bool{true, false} {
+		dir := t.TempDir()
+		slot := filepath.Join(dir, "codexec-pool-1")
+		workspace := filepath.Join(dir, "codexec-42")
+		os.Mkdir(slot, os.ModePerm)
+		if err := adopt(slot, workspace); err != nil {
+			t.Fatal(err)
+		}
+
+		writer := exec.Command("sh", "-c", `i=0; while :; do : > "out_$i"; i=$((i+1)); done`)
+		writer.Dir = workspace
+		if err := writer.Start(); err != nil {
+			t.Fatal(err)
+		}
+		stop := func() {
+			writer.Process.Kill()
+			writer.Wait()
+		}
+		for deadline := time.Now().Add(5 * time.Second); ; {
+			if entries, _ := os.ReadDir(workspace); len(entries) > 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		// Pool.release runs killAll in the container before handBack
+		if killed {
+			stop()
+		}
+
+		err := handBack(workspace, slot)
+		if !killed {
+			stop()
+		}
+		entries, _ := os.ReadDir(slot)
+		switch {
+		case killed && (err != nil || len(entries) != 0):
+			t.Errorf("killed first: handBack = %v, %d entries left in the slot", err, len(entries))
+		case !killed && err == nil && len(entries) != 0:
+			t.Errorf("left running: handBack succeeded with %d entries in the slot", len(entries))
+		case !killed && err != nil && !errors.Is(err, errLeftovers):
+			t.Errorf("left running: handBack = %v", err)
+		}
+		if moved, _ := os.ReadDir(workspace); len(moved) == 0 {
+			t.Errorf("killed %v: the files of the task did not move to the workspace", killed)
+		}
+	}
+}
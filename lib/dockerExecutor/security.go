@@ -0,0 +1,153 @@
+package dockerexecutor
+
+import (
+	"codexec/config"
+	"codexec/lib"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// nobody runs the code when the server itself runs as root.
+const nobody = "65534:65534"
+
+// SecurityProfile is how locked down the containers of an image are, read
+// from [security] in config.toml and [security.images."<image>"] on top.
+type SecurityProfile struct {
+	// User is the uid:gid code runs as, the server's own when not configured
+	User             string
+	DropCapabilities bool
+	AddCapabilities  []string
+	NoNewPrivileges  bool
+	ReadOnlyRootfs   bool
+	// Runtime is an alternative OCI runtime such as runsc, docker's default when empty
+	Runtime string
+}
+
+// ProfileFor returns the security profile of containers running image.
+func ProfileFor(image string) SecurityProfile {
+	paths := func(key string) ([]string, []string) {
+		return []string{"security", "images", image, key}, []string{"security", key}
+	}
+	flag := func(key string, fallback bool) bool {
+		forImage, global := paths(key)
+		return config.GetBoolPath(forImage, config.GetBoolPath(global, fallback))
+	}
+	setting := func(key string, fallback string) string {
+		forImage, global := paths(key)
+		return config.GetStringPath(forImage, config.GetStringPath(global, fallback))
+	}
+	forImage, global := paths("addCapabilities")
+	capabilities, ok := config.GetStringsPath(forImage)
+	if !ok {
+		capabilities, _ = config.GetStringsPath(global)
+	}
+
+	return SecurityProfile{
+		User:             setting("user", defaultUser()),
+		DropCapabilities: flag("dropCapabilities", true),
+		AddCapabilities:  capabilities,
+		NoNewPrivileges:  flag("noNewPrivileges", true),
+		ReadOnlyRootfs:   flag("readOnlyRootfs", true),
+		Runtime:          setting("runtime", ""),
+	}
+}
+
+// defaultUser runs code as the user owning the workspace, so both sides
+// can read and write its files.
+func defaultUser() string {
+	if os.Getuid() == 0 {
+		return nobody
+	}
+	return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+}
+
+// apply locks down the container config and host config of a container.
+func (p SecurityProfile) apply(containerConfig *container.Config, hostConfig *container.HostConfig) {
+	containerConfig.User = p.User
+	// a non-root user with a read-only root has nowhere else to keep caches
+	containerConfig.Env = append(containerConfig.Env, "HOME=/tmp")
+
+	if p.DropCapabilities {
+		hostConfig.CapDrop = []string{"ALL"}
+	}
+	hostConfig.CapAdd = p.AddCapabilities
+	if p.NoNewPrivileges {
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, "no-new-privileges:true")
+	}
+	hostConfig.ReadonlyRootfs = p.ReadOnlyRootfs
+	if p.ReadOnlyRootfs && hostConfig.Tmpfs == nil {
+		hostConfig.Tmpfs = map[string]string{"/tmp": tmpfsOptions}
+	}
+	hostConfig.Runtime = p.Runtime
+}
+
+// ownWorkspace hands the workspace to the container user, only needed when
+// the server runs as root and the user is a numeric uid:gid.
+func (p SecurityProfile) ownWorkspace(dir string) error {
+	uid, gid, ok := p.owner(dir)
+	if !ok {
+		return nil
+	}
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// ownFiles hands the files at paths, relative to the workspace, and the
+// directories leading to them to the container user, the rest of the
+// workspace was handed over when the task got its container.
+func (p SecurityProfile) ownFiles(dir string, paths []string) error {
+	uid, gid, ok := p.owner(dir)
+	if !ok {
+		return nil
+	}
+	owned := make(map[string]bool)
+	for _, name := range paths {
+		cleaned, err := lib.RelativePath(name)
+		if err != nil || cleaned == "." {
+			continue
+		}
+		// a symlink left by the code of the task changes owner itself, what
+		// it points to is not followed
+		path := dir
+		for _, part := range strings.Split(cleaned, string(filepath.Separator)) {
+			path = filepath.Join(path, part)
+			info, err := os.Lstat(path)
+			if err != nil {
+				break
+			}
+			if !owned[path] {
+				owned[path] = true
+				if err := os.Lchown(path, uid, gid); err != nil {
+					return err
+				}
+			}
+			if info.Mode()&fs.ModeSymlink != 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// owner returns the numeric uid and gid files of the workspace dir go to,
+// ok is false when they keep the server as owner.
+func (p SecurityProfile) owner(dir string) (uid int, gid int, ok bool) {
+	if os.Getuid() != 0 {
+		return 0, 0, false
+	}
+	if _, err := fmt.Sscanf(p.User, "%d:%d", &uid, &gid); err != nil {
+		log.Printf("[EXECUTOR] not changing the owner of %s to non numeric user %q", dir, p.User)
+		return 0, 0, false
+	}
+	return uid, gid, true
+}
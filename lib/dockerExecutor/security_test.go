@@ -0,0 +1,124 @@
+package dockerexecutor
+
+import (
+	"codexec/config"
+	codexectypes "codexec/types"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/pelletier/go-toml"
+)
+
+func TestProfileFor(t *testing.T) {
+	tree, err := toml.Load(`
+[security]
+  user = "1000:1000"
+  addCapabilities = ["NET_BIND_SERVICE"]
+
+[security.images."code.buildpack.java"]
+  readOnlyRootfs = false
+  addCapabilities = []
+  runtime = "runsc"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Data = tree
+	defer func() { config.Data = nil }()
+
+	tests := []struct {
+		image string
+		want  SecurityProfile
+	}{
+		{"code.buildpack.python", SecurityProfile{
+			User: "1000:1000", DropCapabilities: true, AddCapabilities: []string{"NET_BIND_SERVICE"},
+			NoNewPrivileges: true, ReadOnlyRootfs: true,
+		}},
+		// the image table overrides the global one, an empty list included
+		{"code.buildpack.java", SecurityProfile{
+			User: "1000:1000", DropCapabilities: true, AddCapabilities: nil,
+			NoNewPrivileges: true, ReadOnlyRootfs: false, Runtime: "runsc",
+		}},
+	}
+	for _, test := range tests {
+		if got := ProfileFor(test.image); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ProfileFor(%s) = %+v, want %+v", test.image, got, test.want)
+		}
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     SecurityProfile
+		tmpfs       map[string]string
+		wantHost    container.HostConfig
+		wantEnvUser string
+	}{
+		{
+			name:    "locked down",
+			profile: SecurityProfile{User: "65534:65534", DropCapabilities: true, AddCapabilities: []string{"CHOWN"}, NoNewPrivileges: true, ReadOnlyRootfs: true, Runtime: "runsc"},
+			wantHost: container.HostConfig{
+				CapDrop: []string{"ALL"}, CapAdd: []string{"CHOWN"}, SecurityOpt: []string{"no-new-privileges:true"},
+				ReadonlyRootfs: true, Tmpfs: map[string]string{"/tmp": tmpfsOptions}, Runtime: "runsc",
+			},
+		},
+		{
+			name:    "keeps the tmpfs of the limits",
+			profile: SecurityProfile{User: "1000:1000", ReadOnlyRootfs: true},
+			tmpfs:   map[string]string{"/tmp": "rw,exec,nosuid,nodev,size=64m"},
+			wantHost: container.HostConfig{
+				ReadonlyRootfs: true, Tmpfs: map[string]string{"/tmp": "rw,exec,nosuid,nodev,size=64m"},
+			},
+		},
+		{
+			name:     "open",
+			profile:  SecurityProfile{User: "1000:1000"},
+			wantHost: container.HostConfig{},
+		},
+	}
+	for _, test := range tests {
+		containerConfig := &container.Config{Env: []string{"HTTP_PROXY=http://proxy"}}
+		hostConfig := &container.HostConfig{Tmpfs: test.tmpfs}
+		test.profile.apply(containerConfig, hostConfig)
+
+		if containerConfig.User != test.profile.User {
+			t.Errorf("%s: user = %q", test.name, containerConfig.User)
+		}
+		if want := []string{"HTTP_PROXY=http://proxy", "HOME=/tmp"}; !reflect.DeepEqual(containerConfig.Env, want) {
+			t.Errorf("%s: env = %v, want %v", test.name, containerConfig.Env, want)
+		}
+		if !reflect.DeepEqual(*hostConfig, test.wantHost) {
+			t.Errorf("%s: host config = %+v, want %+v", test.name, *hostConfig, test.wantHost)
+		}
+	}
+}
+
+// TestDefaultProfileCanRunWhatItBuilds checks that under the default profile
+// HOME sits on a /tmp code can exec from, pip and go build and run there.
+func TestDefaultProfileCanRunWhatItBuilds(t *testing.T) {
+	config.Data = nil
+	for _, limits := range []codexectypes.Limits{{}, {TmpfsMB: 64}} {
+		profile := ProfileFor("code.buildpack.python")
+		if !profile.ReadOnlyRootfs {
+			t.Fatalf("default profile has a writable root")
+		}
+		containerConfig := &container.Config{}
+		hostConfig := &container.HostConfig{Tmpfs: tmpfs(limits)}
+		profile.apply(containerConfig, hostConfig)
+
+		options := strings.Split(hostConfig.Tmpfs["/tmp"], ",")
+		if !slices.Contains(options, "exec") || slices.Contains(options, "noexec") {
+			t.Errorf("tmpfs %d MB: /tmp options = %v, want exec", limits.TmpfsMB, options)
+		}
+		if limits.TmpfsMB > 0 && !slices.Contains(options, "size=64m") {
+			t.Errorf("tmpfs %d MB: /tmp options = %v, want the size limit", limits.TmpfsMB, options)
+		}
+		if !slices.Contains(containerConfig.Env, "HOME=/tmp") {
+			t.Errorf("tmpfs %d MB: env = %v, want HOME on /tmp", limits.TmpfsMB, containerConfig.Env)
+		}
+	}
+}
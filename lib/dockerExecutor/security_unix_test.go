@@ -0,0 +1,43 @@
+//go:build unix
+
+package dockerexecutor
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestOwnFilesOfTheRound(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing owners needs root")
+	}
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "src", "pkg"), os.ModePerm)
+	os.WriteFile(filepath.Join(dir, "src", "pkg", "main.py"), nil, 0644)
+	os.WriteFile(filepath.Join(dir, "old.py"), nil, 0644)
+	outside := t.TempDir()
+	os.Symlink(outside, filepath.Join(dir, "link"))
+
+	profile := SecurityProfile{User: "65534:65534"}
+	if err := profile.ownFiles(dir, []string{"src/pkg/main.py", "link/x", "../escape"}); err != nil {
+		t.Fatal(err)
+	}
+
+	owner := func(path string) uint32 {
+		var stat syscall.Stat_t
+		syscall.Lstat(path, &stat)
+		return stat.Uid
+	}
+	for _, path := range []string{"src", "src/pkg", "src/pkg/main.py", "link"} {
+		if uid := owner(filepath.Join(dir, path)); uid != 65534 {
+			t.Errorf("%s is owned by %d", path, uid)
+		}
+	}
+	for _, path := range []string{dir, filepath.Join(dir, "old.py"), outside} {
+		if uid := owner(path); uid != 0 {
+			t.Errorf("%s changed owner to %d", path, uid)
+		}
+	}
+}
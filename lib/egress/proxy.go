@@ -0,0 +1,177 @@
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// hopHeaders only concern one connection and are not forwarded.
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// errInternal is returned when an allowed name resolves to an address of
+// the host or its networks.
+var errInternal = errors.New("destination address is internal")
+
+// cgnat is the shared address space of RFC 6598, not covered by IsPrivate.
+var cgnat = net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Proxy is a forward HTTP proxy that only lets requests through to hosts on
+// its allowlist, HTTPS is tunnelled with CONNECT to port 443. Connections
+// to loopback, link-local and private addresses are refused whatever name
+// led to them, so an allowed name cannot reach the host or its networks.
+type Proxy struct {
+	allowed   []string
+	transport http.RoundTripper
+	dialer    *net.Dialer
+	// allowInternal lets connections to internal addresses through, for tests
+	allowInternal bool
+}
+
+// NewProxy allows hosts, and their subdomains, from allowed.
+func NewProxy(allowed []string) *Proxy {
+	hosts := make([]string, 0, len(allowed))
+	for _, host := range allowed {
+		hosts = append(hosts, strings.ToLower(strings.TrimSuffix(host, ".")))
+	}
+	p := &Proxy{allowed: hosts}
+	// the address is checked once resolved, when it is connected to
+	p.dialer = &net.Dialer{Timeout: 10 * time.Second, Control: p.checkAddress}
+	p.transport = &http.Transport{
+		DialContext:           p.dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	return p
+}
+
+func (p *Proxy) checkAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", errInternal, address)
+	}
+	if !p.allowInternal && Internal(ip) {
+		return fmt.Errorf("%w: %s", errInternal, address)
+	}
+	return nil
+}
+
+// Internal reports whether ip is an address of the host or of a network
+// it is on rather than of the internet.
+func Internal(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip) ||
+		(ip.To4() != nil && ip.To4()[0] == 0)
+}
+
+// Allowed reports whether host is on the allowlist or below a domain that is.
+func (p *Proxy) Allowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range p.allowed {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	if r.URL.Scheme != "http" || !p.Allowed(r.URL.Hostname()) {
+		p.deny(w, r, r.URL.Host)
+		return
+	}
+
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	for _, header := range hopHeaders {
+		out.Header.Del(header)
+	}
+	resp, err := p.transport.RoundTrip(out)
+	if errors.Is(err, errInternal) {
+		p.deny(w, r, r.URL.Host)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for _, header := range hopHeaders {
+		resp.Header.Del(header)
+	}
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	host, port, err := net.SplitHostPort(r.Host)
+	if err != nil || port != "443" || !p.Allowed(host) {
+		p.deny(w, r, r.Host)
+		return
+	}
+
+	upstream, err := p.dialer.DialContext(context.Background(), "tcp", r.Host)
+	if errors.Is(err, errInternal) {
+		p.deny(w, r, r.Host)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunnelling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, buffered, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	client.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+	go func() {
+		// bytes the client sent right after the CONNECT request
+		if buffered.Reader.Buffered() > 0 {
+			io.CopyN(upstream, buffered, int64(buffered.Reader.Buffered()))
+		}
+		io.Copy(upstream, client)
+		upstream.Close()
+	}()
+	io.Copy(client, upstream)
+	client.Close()
+}
+
+func (p *Proxy) deny(w http.ResponseWriter, r *http.Request, target string) {
+	log.Printf("[EGRESS] denied %s %s from %s", r.Method, target, r.RemoteAddr)
+	http.Error(w, "destination not on the egress allowlist", http.StatusForbidden)
+}
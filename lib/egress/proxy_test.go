@@ -0,0 +1,100 @@
+package egress
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "package index")
+	}))
+	defer upstream.Close()
+
+	// the upstream is on loopback, which the proxy refuses otherwise
+	allowing := NewProxy([]string{"127.0.0.1", "pypi.org"})
+	allowing.allowInternal = true
+	proxy := httptest.NewServer(allowing)
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "package index" {
+		t.Errorf("allowed request = %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = client.Get("http://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("request off the allowlist = %d, want 403", resp.StatusCode)
+	}
+
+	for host, want := range map[string]bool{
+		"pypi.org":              true,
+		"files.pypi.org":        true,
+		"PyPI.org.":             true,
+		"notpypi.org":           false,
+		"pypi.org.evil.example": false,
+	} {
+		if got := NewProxy([]string{"pypi.org"}).Allowed(host); got != want {
+			t.Errorf("Allowed(%q) = %t, want %t", host, got, want)
+		}
+	}
+}
+
+func TestProxyRefusesInternalAddresses(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "server config")
+	}))
+	defer upstream.Close()
+
+	// an allowed name resolving to the host is still refused
+	proxy := httptest.NewServer(NewProxy([]string{"localhost", "127.0.0.1"}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	_, port, _ := net.SplitHostPort(upstream.Listener.Addr().String())
+	for _, target := range []string{upstream.URL, "http://localhost:" + port + "/"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("request to %s = %d, want 403", target, resp.StatusCode)
+		}
+	}
+
+	for address, want := range map[string]bool{
+		"127.0.0.1":   true,
+		"10.1.2.3":    true,
+		"172.17.0.1":  true,
+		"192.168.1.1": true,
+		"169.254.1.1": true,
+		"100.64.0.1":  true,
+		"0.0.0.0":     true,
+		"::1":         true,
+		"fd00::1":     true,
+		"fe80::1":     true,
+		"151.101.0.1": false,
+		"2a04:4e42::": false,
+	} {
+		if got := Internal(net.ParseIP(address)); got != want {
+			t.Errorf("Internal(%s) = %t, want %t", address, got, want)
+		}
+	}
+}
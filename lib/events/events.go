@@ -0,0 +1,142 @@
+package events
+
+import (
+	pb "codexec/protos/go"
+	"codexec/types"
+)
+
+func LLMMessage(round int32, content string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_LlmMessage{
+		LlmMessage: &pb.LLMMessage{Round: round, Content: content},
+	}}
+}
+
+func FileExtracted(path string, language string, size int64) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_FileExtracted{
+		FileExtracted: &pb.FileExtracted{Path: path, Language: language, Size: size},
+	}}
+}
+
+// FileRejected reports a code block that was not saved, or a hunk that was
+// not applied, and why.
+func FileRejected(path string, reason string, hunk string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_FileRejected{
+		FileRejected: &pb.FileRejected{Path: path, Reason: reason, Hunk: hunk},
+	}}
+}
+
+func CommandStarted(index int32, command string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_CommandStarted{
+		CommandStarted: &pb.CommandStarted{Index: index, Command: command},
+	}}
+}
+
+func Output(index int32, stream pb.OutputStream, data string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Output{
+		Output: &pb.OutputChunk{Index: index, Stream: stream, Data: data},
+	}}
+}
+
+func CommandExited(index int32, exitCode int32) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_CommandExited{
+		CommandExited: &pb.CommandExited{Index: index, ExitCode: exitCode},
+	}}
+}
+
+// CommandTimedOut reports a command killed for running past its timeout.
+func CommandTimedOut(index int32, exitCode int32) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_CommandExited{
+		CommandExited: &pb.CommandExited{Index: index, ExitCode: exitCode, TimedOut: true},
+	}}
+}
+
+func Retry(round int32, maxRetry int32, exitCode int32) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Retry{
+		Retry: &pb.Retry{Round: round, MaxRetry: maxRetry, ExitCode: exitCode},
+	}}
+}
+
+func Feedback(round int32, content string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Feedback{
+		Feedback: &pb.Feedback{Round: round, Content: content},
+	}}
+}
+
+func Seeded(source string, files int32, size int64) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Seeded{
+		Seeded: &pb.WorkspaceSeeded{Source: source, Files: files, Size: size},
+	}}
+}
+
+func ImageProgress(image string, action pb.ImageAction, line string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Image{
+		Image: &pb.ImageProgress{Image: image, Action: action, Line: line},
+	}}
+}
+
+func ToolCall(round int32, id string, name string, arguments string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_ToolCall{
+		ToolCall: &pb.ToolCall{Round: round, Id: id, Name: name, Arguments: arguments},
+	}}
+}
+
+func ToolResult(round int32, id string, name string, content string, failed bool) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_ToolResult{
+		ToolResult: &pb.ToolResult{Round: round, Id: id, Name: name, Content: content, Failed: failed},
+	}}
+}
+
+func Terminated(reason string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Terminated{
+		Terminated: &pb.Terminated{Reason: reason},
+	}}
+}
+
+// Finished reports the model ending a task with the finish tool.
+func Finished(result string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Terminated{
+		Terminated: &pb.Terminated{Reason: "finish", Result: result},
+	}}
+}
+
+func Error(code string, message string) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Error{
+		Error: &pb.Error{Code: code, Message: message},
+	}}
+}
+
+func Summary(stats types.InstrumentationStats) *pb.CodeResponse {
+	summary := &pb.Summary{
+		Rounds:         stats.Rounds,
+		LlmTokens:      int64(stats.LLMTokens),
+		TimeTaken:      stats.TimeTaken,
+		Usage:          TokenUsage(stats.Usage),
+		Outcome:        stats.Outcome,
+		ExitCode:       int32(stats.ExitCode),
+		Files:          stats.Files,
+		WallTimeMillis: stats.WallTime.Milliseconds(),
+		Network:        stats.Network,
+	}
+	for _, usage := range stats.RoundUsage {
+		summary.RoundUsage = append(summary.RoundUsage, TokenUsage(usage))
+	}
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Summary{
+		Summary: summary,
+	}}
+}
+
+func TokenUsage(usage types.TokenUsage) *pb.TokenUsage {
+	return &pb.TokenUsage{
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		TotalTokens:      int64(usage.TotalTokens),
+		EstimatedCost:    usage.EstimatedCost,
+		Counted:          usage.Counted,
+	}
+}
+
+func Status(status pb.TaskStatus) *pb.CodeResponse {
+	return &pb.CodeResponse{Event: &pb.CodeResponse_Status{
+		Status: status,
+	}}
+}
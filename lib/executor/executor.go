@@ -0,0 +1,254 @@
+package executor
+
+import (
+	"codexec/lib"
+	"codexec/lib/buildpack"
+	"codexec/lib/events"
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+type Params struct {
+	ContainerName    string
+	DockerImage      string
+	WorkingDirectory string
+	Limits           codexectypes.Limits
+	Network          pb.NetworkPolicy
+	Events           codexectypes.EventEmitter
+	Context          context.Context
+	Cancel           context.CancelFunc
+	// Blocks are the files saved from the reply of the round, in the order
+	// they were written, see buildpack.Plan. With Commands they are only
+	// the files written since the last run.
+	Blocks []lib.SavedCodeBlock
+	// Buildpack says how source files are installed and run in the image,
+	// nil for the defaults of their language
+	Buildpack *codexectypes.Buildpack
+	// Commands are run instead of the plan of Blocks when set, as the
+	// run_command tool does
+	Commands []string
+}
+
+type Response struct {
+	ExitCode int
+	// Stdout and Stderr hold the end of what the commands of a round wrote
+	Stdout string
+	Stderr string
+	// TimedOut is set when a command ran past Limits.CommandTimeout and was killed
+	TimedOut bool
+	// Network is the policy the commands ran with
+	Network pb.NetworkPolicy
+}
+
+// planFor returns the commands a round runs.
+func planFor(params Params) []string {
+	if params.Commands != nil {
+		return params.Commands
+	}
+	return buildpack.Plan(params.WorkingDirectory, params.Blocks, params.Buildpack)
+}
+
+// TimeoutExitCode is reported for a killed command, as timeout(1) does.
+const TimeoutExitCode = 124
+
+// Executor runs the code blocks saved in a task working directory.
+type Executor interface {
+	// Run returns an ExecutorError when the commands could not run to the
+	// end, the response holds what they wrote until then. A command exiting
+	// non-zero is not an error.
+	Run(params Params) (Response, error)
+	// Release is called once the task is over, whatever Run kept for it
+	// between rounds goes.
+	Release(params Params)
+}
+
+// Backend is where commands actually run, a docker container or a local
+// sandboxed process.
+type Backend interface {
+	// Prepare readies the sandbox of a task for a round and returns the
+	// network policy it applies, it is called before every round.
+	Prepare(ctx context.Context, params Params) (pb.NetworkPolicy, error)
+	// Exec runs command in the sandbox and writes its output to stdout and
+	// stderr as it comes. The command is killed once ctx ends.
+	Exec(ctx context.Context, params Params, command string, stdout io.Writer, stderr io.Writer) (int, error)
+	// Teardown releases the sandbox of a finished task.
+	Teardown(params Params)
+}
+
+// Kinds of ExecutorError, match them with errors.Is.
+var (
+	ErrImageNotFound = errors.New("image not found")
+	ErrCreate        = errors.New("sandbox could not be created")
+	ErrExec          = errors.New("command could not be run")
+	ErrTimeout       = errors.New("command timed out")
+	ErrCancelled     = errors.New("execution cancelled")
+)
+
+// ExecutorError is why Run stopped, Kind is one of the errors above and
+// Code the step of the backend that failed, e.g. `docker:container:create`.
+type ExecutorError struct {
+	Kind error
+	Code string
+	Err  error
+}
+
+func (e ExecutorError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%s: %v", e.Code, e.Kind)
+	}
+	return fmt.Sprintf("%s: %v: %v", e.Code, e.Kind, e.Err)
+}
+
+func (e ExecutorError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ErrorCode returns the code clients see in the error event of err, e.g.
+// `executor:image_not_found`.
+func ErrorCode(err error) string {
+	switch {
+	case errors.Is(err, ErrImageNotFound):
+		return "executor:image_not_found"
+	case errors.Is(err, ErrCreate):
+		return "executor:create"
+	case errors.Is(err, ErrTimeout):
+		return "executor:timeout"
+	case errors.Is(err, ErrCancelled):
+		return "executor:cancelled"
+	}
+	return "executor:exec"
+}
+
+// asExecutorError gives err the kind when a backend returned a plain error.
+func asExecutorError(err error, kind error, code string) error {
+	var executorErr ExecutorError
+	if errors.As(err, &executorErr) {
+		return err
+	}
+	return ExecutorError{Kind: kind, Code: code, Err: err}
+}
+
+// Runner is the Executor on top of a Backend, it runs the commands of a
+// round one after the other, streams and records their output and enforces
+// the command timeout.
+type Runner struct {
+	Backend Backend
+}
+
+func New(backend Backend) *Runner {
+	return &Runner{Backend: backend}
+}
+
+func (r *Runner) Release(params Params) {
+	r.Backend.Teardown(params)
+}
+
+// LogPath is the file in the workspace of a task that keeps the output of
+// all its commands.
+func LogPath(workingDirectory string, containerName string) string {
+	return filepath.Join(workingDirectory, fmt.Sprintf("%s_output.log", containerName))
+}
+
+// ResetLog empties the output log of a task, at its start.
+func ResetLog(workingDirectory string, containerName string) {
+	if err := os.Truncate(LogPath(workingDirectory, containerName), 0); err != nil && !os.IsNotExist(err) {
+		log.Printf("[EXECUTOR] failed to reset the output log of %s: %v", containerName, err)
+	}
+}
+
+func (r *Runner) Run(params Params) (Response, error) {
+	var executeResponse Response
+	ctx := params.Context
+
+	if ctx.Err() != nil {
+		log.Println("Got cancel request")
+		executeResponse.Stdout = "canceled"
+		return executeResponse, ExecutorError{Kind: ErrCancelled, Code: "executor:run", Err: ctx.Err()}
+	}
+
+	network, err := r.Backend.Prepare(ctx, params)
+	if err != nil {
+		log.Printf("[EXECUTOR] %v", err)
+		if ctx.Err() != nil {
+			return executeResponse, ExecutorError{Kind: ErrCancelled, Code: "executor:prepare", Err: ctx.Err()}
+		}
+		return executeResponse, asExecutorError(err, ErrCreate, "executor:prepare")
+	}
+	executeResponse.Network = network
+
+	commands := planFor(params)
+
+	// every run of a task adds to its log, ResetLog empties it when the task starts
+	logFileName := LogPath(params.WorkingDirectory, params.ContainerName)
+	var logFile io.Writer = io.Discard
+	if file, err := os.OpenFile(logFileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+		log.Printf("[EXECUTOR] failed to open %s: %v", logFileName, err)
+	} else {
+		defer file.Close()
+		logFile = file
+	}
+
+	stdoutBuf := newTailBuffer(maxCapturedOutput())
+	stderrBuf := newTailBuffer(maxCapturedOutput())
+	output := newOutputStream(params.Events)
+	defer output.Close()
+
+	var runErr error
+	for i, cmd := range commands {
+		index := int32(i)
+
+		log.Printf("[EXECUTOR] - running cmd = %s", cmd)
+		output.Emit(events.CommandStarted(index, cmd))
+
+		cmdCtx, cancel := ctx, context.CancelFunc(func() {})
+		if params.Limits.CommandTimeout > 0 {
+			cmdCtx, cancel = context.WithTimeout(ctx, params.Limits.CommandTimeout)
+		}
+		stdout := io.MultiWriter(stdoutBuf, logFile, output.Writer(index, pb.OutputStream_STDOUT))
+		stderr := io.MultiWriter(stderrBuf, logFile, output.Writer(index, pb.OutputStream_STDERR))
+		exitCode, err := r.Backend.Exec(cmdCtx, params, cmd, stdout, stderr)
+		timedOut := cmdCtx.Err() == context.DeadlineExceeded
+		cancel()
+
+		if ctx.Err() != nil {
+			runErr = ExecutorError{Kind: ErrCancelled, Code: "executor:exec", Err: ctx.Err()}
+			break
+		}
+		if timedOut {
+			log.Printf("[EXECUTOR] - command timed out after %s", params.Limits.CommandTimeout)
+			executeResponse.ExitCode = TimeoutExitCode
+			executeResponse.TimedOut = true
+			output.Emit(events.CommandTimedOut(index, TimeoutExitCode))
+			runErr = ExecutorError{Kind: ErrTimeout, Code: "executor:exec", Err: fmt.Errorf("%q ran longer than %s", cmd, params.Limits.CommandTimeout)}
+			break
+		}
+		if err != nil {
+			log.Printf("[EXECUTOR] %v", err)
+			runErr = asExecutorError(err, ErrExec, "executor:exec")
+			break
+		}
+
+		executeResponse.ExitCode = exitCode
+		output.Emit(events.CommandExited(index, int32(exitCode)))
+
+		if exitCode != 0 {
+			log.Printf("Command '%s' exited with status code: %d (log: %s)\n", cmd, exitCode, logFileName)
+			break
+		}
+	}
+
+	if errors.Is(runErr, ErrCancelled) {
+		executeResponse.Stdout = "canceled"
+	} else {
+		executeResponse.Stdout = stdoutBuf.String()
+		executeResponse.Stderr = stderrBuf.String()
+	}
+	return executeResponse, runErr
+}
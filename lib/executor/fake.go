@@ -0,0 +1,88 @@
+package executor
+
+import (
+	"codexec/lib/events"
+	pb "codexec/protos/go"
+	"sync"
+)
+
+// Fake returns canned responses, one per round, instead of running
+// anything. The last response is repeated once the list runs out.
+type Fake struct {
+	mu        sync.Mutex
+	responses []Response
+	err       error
+	runs      []Params
+	released  []string
+}
+
+func NewFake(responses ...Response) *Fake {
+	return &Fake{responses: responses}
+}
+
+// Fail makes the following runs return err without running anything.
+func (f *Fake) Fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+func (f *Fake) Run(params Params) (Response, error) {
+	f.mu.Lock()
+	f.runs = append(f.runs, params)
+	if f.err != nil {
+		err := f.err
+		f.mu.Unlock()
+		return Response{}, err
+	}
+	response := Response{}
+	if len(f.responses) > 0 {
+		response = f.responses[0]
+		if len(f.responses) > 1 {
+			f.responses = f.responses[1:]
+		}
+	}
+	if response.Network == pb.NetworkPolicy_NETWORK_UNSET {
+		response.Network = params.Network
+	}
+	f.mu.Unlock()
+
+	// report the commands the way a container run would
+	for i, cmd := range planFor(params) {
+		index := int32(i)
+		params.Events.Emit(events.CommandStarted(index, cmd))
+		params.Events.Emit(events.Output(index, pb.OutputStream_STDOUT, response.Stdout))
+		if response.Stderr != "" {
+			params.Events.Emit(events.Output(index, pb.OutputStream_STDERR, response.Stderr))
+		}
+		if response.TimedOut {
+			params.Events.Emit(events.CommandTimedOut(index, int32(response.ExitCode)))
+		} else {
+			params.Events.Emit(events.CommandExited(index, int32(response.ExitCode)))
+		}
+	}
+	if response.TimedOut {
+		return response, ExecutorError{Kind: ErrTimeout, Code: "fake:exec"}
+	}
+	return response, nil
+}
+
+func (f *Fake) Release(params Params) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.released = append(f.released, params.ContainerName)
+}
+
+// Released returns the container names of the tasks released so far.
+func (f *Fake) Released() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.released...)
+}
+
+// Runs returns the parameters of every round executed so far.
+func (f *Fake) Runs() []Params {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Params(nil), f.runs...)
+}
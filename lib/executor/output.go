@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"codexec/config"
+	"codexec/lib/events"
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+)
+
+// maxCapturedOutput is how much of each stream a response keeps, from the end.
+func maxCapturedOutput() int {
+	return config.GetInt("executor.maxOutputKB", 64) << 10
+}
+
+// tailBuffer keeps the last max bytes written to it, a long build log is
+// mostly noise before the error at its end.
+type tailBuffer struct {
+	max     int
+	data    []byte
+	dropped int
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.max; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+		b.dropped += over
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	if b.dropped > 0 {
+		return fmt.Sprintf("[... %d bytes truncated ...]\n%s", b.dropped, b.data)
+	}
+	return string(b.data)
+}
+
+// outputStream forwards what commands write as Output events from its own
+// goroutine. The writers never block on the consumer, a slow one gets fewer
+// and larger chunks instead and, past maxPending bytes, loses the oldest
+// output rather than stalling the container. Other events of the commands
+// go through Emit to keep their order with the output.
+type outputStream struct {
+	events     codexectypes.EventEmitter
+	interval   time.Duration
+	maxPending int
+
+	mu      sync.Mutex
+	pending []*outputChunk
+	size    int
+	dropped int
+	closed  bool
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+// outputChunk is either output of a command or an event queued with Emit.
+type outputChunk struct {
+	index  int32
+	stream pb.OutputStream
+	data   []byte
+	event  *pb.CodeResponse
+}
+
+func newOutputStream(emitter codexectypes.EventEmitter) *outputStream {
+	s := &outputStream{
+		events:     emitter,
+		interval:   time.Duration(config.GetInt("executor.outputFlushMillis", 50)) * time.Millisecond,
+		maxPending: config.GetInt("executor.maxPendingOutputKB", 1024) << 10,
+		wake:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Writer returns the writer for one stream of the command at index.
+func (s *outputStream) Writer(index int32, stream pb.OutputStream) io.Writer {
+	return &outputWriter{s, index, stream}
+}
+
+// Emit queues event behind the output written so far.
+func (s *outputStream) Emit(event *pb.CodeResponse) {
+	s.mu.Lock()
+	s.pending = append(s.pending, &outputChunk{event: event})
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Close sends what is still pending and stops the stream.
+func (s *outputStream) Close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.signal()
+	<-s.done
+}
+
+func (s *outputStream) add(index int32, stream pb.OutputStream, p []byte) {
+	s.mu.Lock()
+	if last := len(s.pending) - 1; last >= 0 && s.pending[last].event == nil && s.pending[last].index == index && s.pending[last].stream == stream {
+		s.pending[last].data = append(s.pending[last].data, p...)
+	} else {
+		s.pending = append(s.pending, &outputChunk{index: index, stream: stream, data: append([]byte(nil), p...)})
+	}
+	s.size += len(p)
+	for _, chunk := range s.pending {
+		if s.size <= s.maxPending {
+			break
+		}
+		drop := min(len(chunk.data), s.size-s.maxPending)
+		chunk.data = chunk.data[drop:]
+		s.size -= drop
+		s.dropped += drop
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+func (s *outputStream) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *outputStream) run() {
+	defer close(s.done)
+	for {
+		<-s.wake
+		s.mu.Lock()
+		pending, dropped, closed := s.pending, s.dropped, s.closed
+		s.pending, s.size, s.dropped = nil, 0, 0
+		s.mu.Unlock()
+
+		if dropped > 0 {
+			log.Printf("[EXECUTOR] dropped %d bytes of output produced faster than it could be sent", dropped)
+		}
+		for _, chunk := range pending {
+			if chunk.event != nil {
+				s.events.Emit(chunk.event)
+				continue
+			}
+			if dropped > 0 {
+				chunk.data = append([]byte(fmt.Sprintf("[... %d bytes dropped ...]\n", dropped)), chunk.data...)
+				dropped = 0
+			}
+			for data := chunk.data; len(data) > 0; {
+				n := min(len(data), maxOutputChunk)
+				s.events.Emit(events.Output(chunk.index, chunk.stream, string(data[:n])))
+				data = data[n:]
+			}
+		}
+		if closed {
+			return
+		}
+		// let output gather for a moment instead of one event per write
+		time.Sleep(s.interval)
+	}
+}
+
+// maxOutputChunk keeps single Output events small enough to interleave.
+const maxOutputChunk = 32 * 1024
+
+type outputWriter struct {
+	stream *outputStream
+	index  int32
+	kind   pb.OutputStream
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.stream.add(w.index, w.kind, p)
+	return len(p), nil
+}
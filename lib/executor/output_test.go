@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"codexec/lib/events"
+	pb "codexec/protos/go"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowEmitter takes a while for every event, like a stalled consumer.
+type slowEmitter struct {
+	mu     sync.Mutex
+	events []*pb.CodeResponse
+}
+
+func (e *slowEmitter) Emit(event *pb.CodeResponse) {
+	time.Sleep(10 * time.Millisecond)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func TestOutputStreamDoesNotBlockWriters(t *testing.T) {
+	emitter := &slowEmitter{}
+	output := newOutputStream(emitter)
+	output.maxPending = 1000
+
+	output.Emit(events.CommandStarted(0, "sh codeblock_1.sh"))
+	stdout := output.Writer(0, pb.OutputStream_STDOUT)
+	started := time.Now()
+	for i := 0; i < 500; i++ {
+		stdout.Write([]byte("downloading package ...\n"))
+	}
+	if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+		t.Errorf("writes took %s, they waited on the consumer", elapsed)
+	}
+	output.Emit(events.CommandExited(0, 0))
+	output.Close()
+
+	var text string
+	for i, event := range emitter.events {
+		switch {
+		case i == 0 && event.GetCommandStarted() == nil:
+			t.Errorf("first event = %v, want commandStarted", event)
+		case i == len(emitter.events)-1 && event.GetCommandExited() == nil:
+			t.Errorf("last event = %v, want commandExited", event)
+		case event.GetOutput() != nil:
+			text += event.GetOutput().Data
+		}
+	}
+	if !strings.HasPrefix(text, "[... ") || !strings.HasSuffix(text, "downloading package ...\n") {
+		t.Errorf("output does not note the dropped bytes before the latest ones: %q", text)
+	}
+	if len(emitter.events) > 20 {
+		t.Errorf("%d events, output was not coalesced", len(emitter.events))
+	}
+}
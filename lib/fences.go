@@ -0,0 +1,293 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CodeBlock is a fenced code block of a markdown reply.
+type CodeBlock struct {
+	// Language is the first word of the info string, e.g. python
+	Language string
+	// Info is the whole info string after the opening fence
+	Info string
+	// Attributes are the key=value pairs of the info string, bare words
+	// are set to "true", e.g. `python title=app.py skip`
+	Attributes map[string]string
+	Body       string
+	// Line and EndLine are the 1-based lines of the opening and closing
+	// fence, EndLine is the last line of the reply when the block is not
+	// closed.
+	Line    int
+	EndLine int
+}
+
+// listItem matches the marker of a list item and the spaces after it, the
+// content of the item starts after the match.
+var listItem = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d{1,9}[.)])(?: {1,4}|$)`)
+
+// blockQuote matches one blockquote marker.
+var blockQuote = regexp.MustCompile(`^ {0,3}> ?`)
+
+// filenameComments match a `filename:` comment in a block body, in the
+// comment styles of the languages the model writes.
+var filenameComments = []*regexp.Regexp{
+	regexp.MustCompile(`(?mi)^\s*(?:#|//|--|;)\s*filename\s*:\s*(\S+)`),
+	regexp.MustCompile(`(?mi)^\s*/\*\s*filename\s*:\s*(\S+?)\s*\*/`),
+	regexp.MustCompile(`(?mi)^\s*<!--\s*filename\s*:\s*(\S+?)\s*-->`),
+}
+
+// titledFile matches a title naming a file, a path without spaces ending in
+// an extension with a letter.
+var titledFile = regexp.MustCompile(`^[^\s]*[^\s/.][.][0-9A-Za-z_+-]*[A-Za-z][0-9A-Za-z_+-]*$`)
+
+// ParseCodeBlocks returns the fenced code blocks of markdown in the order
+// they appear. Fences follow CommonMark: three or more backticks or tildes
+// indented by at most three spaces, closed by a fence of the same character
+// at least as long, so shorter fences inside a block are part of its body.
+// Fences inside list items and blockquotes are recognised, their content is
+// taken without the indentation of the opening fence. A block left open
+// runs to the end of the reply.
+func ParseCodeBlocks(markdown string) []CodeBlock {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(markdown, "\n"), "\n")
+
+	var blocks []CodeBlock
+	// listIndent is the content column of the list item a line may belong to
+	listIndent := 0
+	for i := 0; i < len(lines); i++ {
+		line, quotes := unquote(expandTabs(lines[i]), -1)
+		if marker := listItem.FindString(line); marker != "" {
+			listIndent = len(marker)
+			line = strings.Repeat(" ", len(marker)) + line[len(marker):]
+		} else if strings.TrimSpace(line) != "" && indentation(line) < listIndent {
+			listIndent = 0
+		}
+
+		indent := indentation(line)
+		base := 0
+		if indent >= listIndent {
+			base = listIndent
+		}
+		if indent-base > 3 {
+			continue
+		}
+		char, length, info, ok := openingFence(line[indent:])
+		if !ok {
+			continue
+		}
+
+		block := CodeBlock{Info: info, Line: i + 1, EndLine: len(lines)}
+		block.Language, block.Attributes = parseInfo(info)
+		var body strings.Builder
+		for i++; i < len(lines); i++ {
+			content := expandTabs(lines[i])
+			if quotes > 0 {
+				content, _ = unquote(content, quotes)
+			}
+			if closingFence(content, char, length, base) {
+				block.EndLine = i + 1
+				break
+			}
+			body.WriteString(trimIndent(content, indent) + "\n")
+		}
+		block.Body = body.String()
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// Filename returns the name the block asks to be saved as, from a
+// filename, file or path attribute, a title or name attribute that looks
+// like a file name, or else a `filename:` comment in its body (`#`, `//`,
+// `/* */` or `<!-- -->`), empty when it names none.
+func (b CodeBlock) Filename() string {
+	for _, key := range []string{"filename", "file", "path"} {
+		if name := b.Attributes[key]; name != "" && name != "true" {
+			return name
+		}
+	}
+	// a title is often prose, e.g. `text title="Example output"`
+	for _, key := range []string{"title", "name"} {
+		if name := b.Attributes[key]; titledFile.MatchString(name) {
+			return name
+		}
+	}
+	for _, pattern := range filenameComments {
+		if match := pattern.FindStringSubmatch(b.Body); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// Runs reports what the run and skip attributes of a block ask for, ok is
+// false when it has neither.
+func Runs(attributes map[string]string) (run bool, ok bool) {
+	if truthy(attributes["skip"]) || truthy(attributes["norun"]) {
+		return false, true
+	}
+	value, ok := attributes["run"]
+	if !ok {
+		return false, false
+	}
+	return truthy(value), true
+}
+
+func truthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "1", "on":
+		return true
+	}
+	return false
+}
+
+// openingFence parses a fence at the start of line, backtick fences may
+// not have a backtick in their info string.
+func openingFence(line string) (byte, int, string, bool) {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return 0, 0, "", false
+	}
+	char := line[0]
+	length := 0
+	for length < len(line) && line[length] == char {
+		length++
+	}
+	if length < 3 {
+		return 0, 0, "", false
+	}
+	info := strings.TrimSpace(line[length:])
+	if char == '`' && strings.Contains(info, "`") {
+		return 0, 0, "", false
+	}
+	return char, length, info, true
+}
+
+// closingFence reports whether line closes a block opened by length
+// characters char, the fence may be indented by up to three spaces past the
+// list item it is in.
+func closingFence(line string, char byte, length int, base int) bool {
+	indent := indentation(line)
+	if indent-base > 3 && indent > 3 {
+		return false
+	}
+	rest := line[indent:]
+	n := 0
+	for n < len(rest) && rest[n] == char {
+		n++
+	}
+	return n >= length && strings.TrimSpace(rest[n:]) == ""
+}
+
+// parseInfo splits an info string into the language and the attributes,
+// `python title="my app.py"`, `{.python file=app.py}` and
+// `python:app.py` are all understood.
+func parseInfo(info string) (string, map[string]string) {
+	attributes := make(map[string]string)
+	info = strings.TrimSpace(info)
+	if strings.HasPrefix(info, "{") && strings.HasSuffix(info, "}") {
+		info = strings.TrimSpace(info[1 : len(info)-1])
+	}
+
+	language := ""
+	for i, token := range tokenize(info) {
+		key, value, isPair := strings.Cut(token, "=")
+		switch {
+		case isPair:
+			attributes[strings.ToLower(key)] = unquoteValue(value)
+		case i == 0 || (language == "" && strings.HasPrefix(token, ".")):
+			language = strings.TrimPrefix(token, ".")
+			if name, file, ok := strings.Cut(language, ":"); ok && file != "" {
+				language = name
+				attributes["filename"] = file
+			}
+		default:
+			attributes[strings.ToLower(token)] = "true"
+		}
+	}
+	return language, attributes
+}
+
+// tokenize splits s on spaces and commas outside of double or single quotes.
+func tokenize(s string) []string {
+	var tokens []string
+	var token strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			token.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			token.WriteRune(r)
+		case r == ' ' || r == '\t' || r == ',':
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteRune(r)
+		}
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+func unquoteValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// unquote strips up to max blockquote markers of line, all of them when
+// max is negative, and returns how many there were.
+func unquote(line string, max int) (string, int) {
+	quotes := 0
+	for max < 0 || quotes < max {
+		marker := blockQuote.FindString(line)
+		if marker == "" {
+			break
+		}
+		line = line[len(marker):]
+		quotes++
+	}
+	return line, quotes
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// trimIndent removes up to n leading spaces of line.
+func trimIndent(line string, n int) string {
+	indent := indentation(line)
+	if indent > n {
+		indent = n
+	}
+	return line[indent:]
+}
+
+// expandTabs turns the tabs of the indentation of line into spaces, to the
+// next multiple of four as CommonMark does.
+func expandTabs(line string) string {
+	rest := strings.TrimLeft(line, " \t")
+	leading := line[:len(line)-len(rest)]
+	if !strings.Contains(leading, "\t") {
+		return line
+	}
+	column := 0
+	for _, r := range leading {
+		if r == '\t' {
+			column += 4 - column%4
+		} else {
+			column++
+		}
+	}
+	return strings.Repeat(" ", column) + rest
+}
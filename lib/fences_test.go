@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"testing"
+)
+
+func TestParseCodeBlocks(t *testing.T) {
+	reply := "Here is the app:\n" +
+		"\n" +
+		"````markdown\n" +
+		"```python\n" +
+		"print(1)\n" +
+		"```\n" +
+		"````\n" +
+		"\n" +
+		"1. Install the dependencies:\n" +
+		"   ```bash file=\"setup script.sh\" skip\n" +
+		"   pip install requests\n" +
+		"   ```\n" +
+		"\n" +
+		"> ~~~{.js file=index.js}\n" +
+		"> console.log(1)\n" +
+		"> ~~~\n" +
+		"\n" +
+		"```go\n" +
+		"// filename: main.go\n" +
+		"package main\n" +
+		"```\n" +
+		"\n" +
+		"```html\n" +
+		"<!-- filename: page.html -->\n" +
+		"<p>hi</p>\n"
+
+	blocks := ParseCodeBlocks(reply)
+	if len(blocks) != 5 {
+		t.Fatalf("parsed %d blocks, want 5: %+v", len(blocks), blocks)
+	}
+
+	want := []struct {
+		language, filename, body string
+		line, endLine            int
+	}{
+		{"markdown", "", "```python\nprint(1)\n```\n", 3, 7},
+		{"bash", "setup script.sh", "pip install requests\n", 10, 12},
+		{"js", "index.js", "console.log(1)\n", 14, 16},
+		{"go", "main.go", "// filename: main.go\npackage main\n", 18, 21},
+		{"html", "page.html", "<!-- filename: page.html -->\n<p>hi</p>\n", 23, 25},
+	}
+	for i, w := range want {
+		block := blocks[i]
+		if block.Language != w.language || block.Filename() != w.filename || block.Body != w.body {
+			t.Errorf("block %d = %q %q %q, want %q %q %q", i, block.Language, block.Filename(), block.Body, w.language, w.filename, w.body)
+		}
+		if block.Line != w.line || block.EndLine != w.endLine {
+			t.Errorf("block %d lines = %d-%d, want %d-%d", i, block.Line, block.EndLine, w.line, w.endLine)
+		}
+	}
+
+	if run, ok := Runs(blocks[1].Attributes); !ok || run {
+		t.Errorf("Runs(%v) = %v, %v, want false, true", blocks[1].Attributes, run, ok)
+	}
+	if _, ok := Runs(blocks[3].Attributes); ok {
+		t.Errorf("Runs(%v) asks for something", blocks[3].Attributes)
+	}
+}
+
+func TestParseInfo(t *testing.T) {
+	language, attributes := parseInfo("python:app.py run=no")
+	if language != "python" || attributes["filename"] != "app.py" {
+		t.Errorf("parseInfo = %q %v", language, attributes)
+	}
+	if run, ok := Runs(attributes); !ok || run {
+		t.Errorf("Runs(%v) = %v, %v, want false, true", attributes, run, ok)
+	}
+}
+
+func TestFilenameOfTitles(t *testing.T) {
+	for info, want := range map[string]string{
+		`text title="Example output"`:     "",
+		`text title="Output of main.py"`:  "",
+		`text title="Version 1.2"`:        "",
+		`python title=app.py`:             "app.py",
+		`python title=src/app.py`:         "src/app.py",
+		`js name=".eslintrc.js"`:          ".eslintrc.js",
+		`text title=Dockerfile`:           "",
+		`text path="docs/notes on it.md"`: "docs/notes on it.md",
+	} {
+		language, attributes := parseInfo(info)
+		block := CodeBlock{Language: language, Attributes: attributes}
+		if got := block.Filename(); got != want {
+			t.Errorf("Filename of %s = %q, want %q", info, got, want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CloneRepository checks out ref, the default branch when empty, of the
+// repository at source into dir. Only the files are kept, not the history.
+func CloneRepository(ctx context.Context, source string, ref string, dir string) error {
+	source, err := filepath.Abs(source)
+	if err != nil {
+		return err
+	}
+
+	// a file:// url rather than a plain path so --depth is honoured
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "file://"+filepath.ToSlash(source), dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git clone failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return os.RemoveAll(filepath.Join(dir, ".git"))
+}
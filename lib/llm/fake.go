@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+var ErrScriptExhausted = errors.New("fake llm: no scripted response left")
+
+// toolPrefix marks a scripted response that calls a tool instead of
+// replying with text, e.g. `tool:finish {"result": "done"}`.
+const toolPrefix = "tool:"
+
+// Fake replays scripted responses in order, one per GenerateContent call.
+type Fake struct {
+	mu        sync.Mutex
+	responses []string
+	calls     [][]llms.MessageContent
+	toolCalls int
+}
+
+func NewFake(responses ...string) *Fake {
+	return &Fake{responses: responses}
+}
+
+func (f *Fake) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, messages)
+	if len(f.responses) == 0 {
+		f.mu.Unlock()
+		return nil, ErrScriptExhausted
+	}
+	response := f.responses[0]
+	f.responses = f.responses[1:]
+	if strings.HasPrefix(response, toolPrefix) {
+		f.toolCalls++
+		name, arguments, _ := strings.Cut(strings.TrimPrefix(response, toolPrefix), " ")
+		call := llms.ToolCall{
+			ID:           fmt.Sprintf("call_%d", f.toolCalls),
+			Type:         "function",
+			FunctionCall: &llms.FunctionCall{Name: name, Arguments: arguments},
+		}
+		f.mu.Unlock()
+		return &llms.ContentResponse{
+			Choices: []*llms.ContentChoice{{StopReason: "tool_calls", FuncCall: call.FunctionCall, ToolCalls: []llms.ToolCall{call}}},
+		}, nil
+	}
+	f.mu.Unlock()
+
+	opts := llms.CallOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		if err := opts.StreamingFunc(ctx, []byte(response)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{Content: response, StopReason: "stop"}},
+	}, nil
+}
+
+// Calls returns the conversations the fake was asked to continue.
+func (f *Fake) Calls() [][]llms.MessageContent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([][]llms.MessageContent(nil), f.calls...)
+}
+
+func (f *Fake) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, f, prompt, options...)
+}
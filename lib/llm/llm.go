@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"codexec/config"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/anthropic"
+	"github.com/tmc/langchaingo/llms/ollama"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// ErrUnknownProvider is returned for a provider without a table in config.toml.
+var ErrUnknownProvider = errors.New("unknown llm provider")
+
+// Factory builds a chat model for one provider, settings are the
+// [llm.providers.<name>] section of config.toml.
+type Factory func(model string, settings Settings) (llms.Model, error)
+
+type Settings struct {
+	Name       string
+	Type       string
+	APIKey     string
+	BaseURL    string
+	APIVersion string
+	Responses  []string
+}
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		"openai":    newOpenAI,
+		"azure":     newAzure,
+		"anthropic": newAnthropic,
+		"ollama":    newOllama,
+		"fake":      newFake,
+	}
+)
+
+// Register adds a provider type, or replaces a built-in one.
+func Register(providerType string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[providerType] = factory
+}
+
+// DefaultProvider is the provider used when a request does not name one.
+func DefaultProvider() string {
+	return config.GetString("llm.defaultProvider", "openai")
+}
+
+// New returns a chat model of the named provider, an empty name selects
+// the default provider.
+func New(provider string, model string) (llms.Model, error) {
+	settings, err := Load(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	mu.RLock()
+	factory, ok := factories[settings.Type]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown llm provider type %q for provider %q", settings.Type, settings.Name)
+	}
+	return factory(model, settings)
+}
+
+// Load reads the settings of the named provider, one of the
+// `[llm.providers.<name>]` tables of config.toml. The provider type defaults
+// to its name so `[llm.providers.openai]` needs no `type`. The fake type
+// is only available with `llm.allowFake`, which test configs set.
+func Load(provider string) (Settings, error) {
+	if provider == "" {
+		provider = DefaultProvider()
+	}
+	section := []string{"llm", "providers", provider}
+	if !config.HasTable(section) {
+		return Settings{}, fmt.Errorf("%w %q", ErrUnknownProvider, provider)
+	}
+	key := func(name string) []string {
+		return append(section[:len(section):len(section)], name)
+	}
+
+	settings := Settings{
+		Name:       provider,
+		Type:       config.GetStringPath(key("type"), provider),
+		APIKey:     config.GetStringPath(key("apiKey"), ""),
+		BaseURL:    config.GetStringPath(key("baseURL"), ""),
+		APIVersion: config.GetStringPath(key("apiVersion"), ""),
+	}
+	settings.Responses, _ = config.GetStringsPath(key("responses"))
+	if settings.Type == "fake" && !config.GetBoolPath([]string{"llm", "allowFake"}, false) {
+		return Settings{}, fmt.Errorf("llm provider %q is fake, set llm.allowFake to use it", provider)
+	}
+	// credentials may name the environment variable holding them
+	if settings.APIKey == "" {
+		if env := config.GetStringPath(key("apiKeyEnv"), defaultKeyEnv(settings.Type)); env != "" {
+			settings.APIKey = os.Getenv(env)
+		}
+	}
+	return settings, nil
+}
+
+func defaultKeyEnv(providerType string) string {
+	switch providerType {
+	case "openai":
+		return "OPENAI_API_KEY"
+	case "anthropic":
+		return "ANTHROPIC_API_KEY"
+	}
+	return ""
+}
+
+func newOpenAI(model string, settings Settings) (llms.Model, error) {
+	token := settings.APIKey
+	if token == "" {
+		if settings.BaseURL == "" {
+			return nil, fmt.Errorf("llm provider %q has no apiKey", settings.Name)
+		}
+		// local OpenAI compatible servers usually do not check the key
+		token = "none"
+	}
+
+	options := []openai.Option{openai.WithModel(model), openai.WithToken(token)}
+	if settings.BaseURL != "" {
+		options = append(options, openai.WithBaseURL(settings.BaseURL))
+	}
+	return openai.New(options...)
+}
+
+// newAzure talks to an Azure OpenAI resource, the model is the deployment name.
+func newAzure(model string, settings Settings) (llms.Model, error) {
+	if settings.APIKey == "" || settings.BaseURL == "" {
+		return nil, fmt.Errorf("llm provider %q needs apiKey and baseURL", settings.Name)
+	}
+	apiVersion := settings.APIVersion
+	if apiVersion == "" {
+		apiVersion = openai.DefaultAPIVersion
+	}
+	return openai.New(
+		openai.WithAPIType(openai.APITypeAzure),
+		openai.WithToken(settings.APIKey),
+		openai.WithBaseURL(settings.BaseURL),
+		openai.WithAPIVersion(apiVersion),
+		openai.WithModel(model),
+	)
+}
+
+func newAnthropic(model string, settings Settings) (llms.Model, error) {
+	if settings.APIKey == "" {
+		return nil, fmt.Errorf("llm provider %q has no apiKey", settings.Name)
+	}
+	options := []anthropic.Option{anthropic.WithModel(model), anthropic.WithToken(settings.APIKey)}
+	if settings.BaseURL != "" {
+		options = append(options, anthropic.WithBaseURL(settings.BaseURL))
+	}
+	return anthropic.New(options...)
+}
+
+func newOllama(model string, settings Settings) (llms.Model, error) {
+	options := []ollama.Option{ollama.WithModel(model)}
+	if settings.BaseURL != "" {
+		options = append(options, ollama.WithServerURL(settings.BaseURL))
+	}
+	return ollama.New(options...)
+}
+
+func newFake(model string, settings Settings) (llms.Model, error) {
+	return NewFake(settings.Responses...), nil
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"codexec/config"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pelletier/go-toml"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func setConfig(t *testing.T, data string) {
+	t.Helper()
+	tree, err := toml.Load(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Data = tree
+	t.Cleanup(func() { config.Data = nil })
+}
+
+const providers = `
+[llm]
+  defaultProvider = "openai"
+  allowFake = true
+
+[llm.providers.openai]
+  apiKeyEnv = "CODEXEC_TEST_KEY"
+
+[llm.providers.local]
+  type = "openai"
+  baseURL = "http://localhost:8000/v1"
+
+[llm.providers.replay]
+  type = "fake"
+  responses = ["hello"]
+`
+
+func TestLoad(t *testing.T) {
+	setConfig(t, providers)
+	t.Setenv("CODEXEC_TEST_KEY", "secret")
+
+	settings, err := Load("")
+	if err != nil || settings.Name != "openai" || settings.Type != "openai" || settings.APIKey != "secret" {
+		t.Errorf("Load of the default = %+v, %v", settings, err)
+	}
+	settings, err = Load("local")
+	if err != nil || settings.Type != "openai" || settings.BaseURL != "http://localhost:8000/v1" || settings.APIKey != "" {
+		t.Errorf("Load(local) = %+v, %v", settings, err)
+	}
+
+	// names are matched against the tables, never spliced into other keys
+	for _, name := range []string{"anthropic", "local.baseURL", "openai.apiKeyEnv", "fake", "../openai"} {
+		if _, err := Load(name); !errors.Is(err, ErrUnknownProvider) {
+			t.Errorf("Load(%q) = %v, want ErrUnknownProvider", name, err)
+		}
+	}
+}
+
+func TestFakeIsOnlyForTests(t *testing.T) {
+	setConfig(t, providers)
+	if settings, err := Load("replay"); err != nil || len(settings.Responses) != 1 {
+		t.Errorf("Load(replay) = %+v, %v", settings, err)
+	}
+
+	config.Data.SetPath([]string{"llm", "allowFake"}, false)
+	if _, err := Load("replay"); err == nil {
+		t.Error("fake provider loaded without llm.allowFake")
+	}
+	if _, err := New("replay", "model"); err == nil {
+		t.Error("fake model created without llm.allowFake")
+	}
+}
+
+func TestNew(t *testing.T) {
+	setConfig(t, providers)
+	t.Setenv("CODEXEC_TEST_KEY", "")
+
+	model, err := New("replay", "model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := llms.GenerateFromSinglePrompt(context.Background(), model, "hi")
+	if err != nil || reply != "hello" {
+		t.Errorf("fake replied %q, %v", reply, err)
+	}
+
+	if _, err := New("openai", "gpt-4o"); err == nil {
+		t.Error("openai without a key created a model")
+	}
+	if _, err := New("local", "llama"); err != nil {
+		t.Errorf("local OpenAI compatible server = %v", err)
+	}
+	if _, err := New("missing", "model"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("New(missing) = %v, want ErrUnknownProvider", err)
+	}
+}
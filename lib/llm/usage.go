@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"codexec/config"
+	"codexec/types"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// Usage returns the tokens spent on a completion as reported by the
+// provider, or counted locally over prompt and reply when it reported none.
+func Usage(model string, prompt []llms.MessageContent, choice *llms.ContentChoice) types.TokenUsage {
+	var usage types.TokenUsage
+	if Reported(choice) {
+		info := choice.GenerationInfo
+		usage.PromptTokens = intValue(info, "PromptTokens", "InputTokens")
+		usage.CompletionTokens = intValue(info, "CompletionTokens", "OutputTokens")
+		usage.TotalTokens = intValue(info, "TotalTokens")
+	} else {
+		usage.Counted = true
+		for _, message := range prompt {
+			for _, part := range message.Parts {
+				switch part := part.(type) {
+				case llms.TextContent:
+					usage.PromptTokens += llms.CountTokens(model, part.Text)
+				case llms.ToolCall:
+					if part.FunctionCall != nil {
+						usage.PromptTokens += llms.CountTokens(model, part.FunctionCall.Name+part.FunctionCall.Arguments)
+					}
+				case llms.ToolCallResponse:
+					usage.PromptTokens += llms.CountTokens(model, part.Content)
+				}
+			}
+		}
+		if choice != nil {
+			usage.CompletionTokens = llms.CountTokens(model, choice.Content)
+		}
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	usage.EstimatedCost = Cost(model, usage)
+	return usage
+}
+
+// Reported tells whether the provider reported the tokens of choice.
+func Reported(choice *llms.ContentChoice) bool {
+	if choice == nil {
+		return false
+	}
+	return intValue(choice.GenerationInfo, "PromptTokens", "InputTokens") != 0 ||
+		intValue(choice.GenerationInfo, "CompletionTokens", "OutputTokens") != 0
+}
+
+// Cost estimates the price of usage in USD from the [pricing] table of
+// config.toml, which lists USD per million prompt and completion tokens.
+func Cost(model string, usage types.TokenUsage) float64 {
+	prompt := config.GetFloatPath([]string{"pricing", model, "prompt"}, 0)
+	completion := config.GetFloatPath([]string{"pricing", model, "completion"}, 0)
+	return (float64(usage.PromptTokens)*prompt + float64(usage.CompletionTokens)*completion) / 1e6
+}
+
+// Add accumulates the usage of one round into a task total.
+func Add(total types.TokenUsage, round types.TokenUsage) types.TokenUsage {
+	return types.TokenUsage{
+		PromptTokens:     total.PromptTokens + round.PromptTokens,
+		CompletionTokens: total.CompletionTokens + round.CompletionTokens,
+		TotalTokens:      total.TotalTokens + round.TotalTokens,
+		EstimatedCost:    total.EstimatedCost + round.EstimatedCost,
+		Counted:          total.Counted || round.Counted,
+	}
+}
+
+func intValue(info map[string]any, keys ...string) int {
+	for _, key := range keys {
+		switch value := info[key].(type) {
+		case int:
+			return value
+		case int32:
+			return int(value)
+		case int64:
+			return int(value)
+		case float64:
+			return int(value)
+		}
+	}
+	return 0
+}
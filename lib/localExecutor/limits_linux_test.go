@@ -0,0 +1,39 @@
+package localexecutor
+
+import (
+	codexectypes "codexec/types"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLocalEnforcesLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		limits codexectypes.Limits
+	}{
+		{"memory", "x=$(head -c 100000000 /dev/zero | tr '\\000' a); echo ${#x}\n", codexectypes.Limits{MemoryMB: 32}},
+		{"pids", "for i in 1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16; do sleep 1 & done; wait\n", codexectypes.Limits{Pids: 8}},
+		{"file size", "head -c 2000000 /dev/zero > big\n", codexectypes.Limits{TmpfsMB: 1}},
+	}
+	for _, test := range tests {
+		if test.name == "pids" && os.Geteuid() == 0 {
+			// the kernel does not hold root to the process limit
+			continue
+		}
+		response, _, err := run(t, test.script, test.limits)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if response.ExitCode == 0 {
+			t.Errorf("%s: a command over the limit succeeded, stdout %q", test.name, response.Stdout)
+		}
+	}
+
+	// under the limits the same commands run
+	response, _, err := run(t, "x=$(head -c 1000 /dev/zero | tr '\\000' a); echo ${#x}; sleep 0 & wait\n", codexectypes.Limits{MemoryMB: 256, Pids: 64, TmpfsMB: 1})
+	if err != nil || response.ExitCode != 0 || strings.TrimSpace(response.Stdout) != "1000" {
+		t.Errorf("command within the limits = %+v, %v", response, err)
+	}
+}
@@ -0,0 +1,109 @@
+package localexecutor
+
+import (
+	"codexec/lib/executor"
+	pb "codexec/protos/go"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Local is the executor backend for hosts without a docker daemon, commands
+// run as processes of the server in the task workspace. On Linux they get
+// their own user, pid, mount, ipc and uts namespaces, a network namespace
+// unless the policy is full, and rlimits taken from the task limits.
+//
+// Local is not a sandbox. Commands see the filesystem of the host with the
+// permissions of the server, its config.toml and API keys, the task store
+// and the workspaces of other tasks included. Besides the timeouts only
+// memory, processes and the size of a file written are limited, and only
+// on Linux, see withLimits. Only run code you would run yourself with it.
+//
+// The image of a task is ignored, commands use the tools installed on the
+// host.
+type Local struct {
+	// Namespaces isolates commands in new Linux namespaces, turn it off
+	// where unprivileged user namespaces are not allowed.
+	Namespaces bool
+}
+
+func NewLocal(namespaces bool) *Local {
+	return &Local{Namespaces: namespaces}
+}
+
+func (l *Local) Prepare(ctx context.Context, params executor.Params) (pb.NetworkPolicy, error) {
+	if err := os.MkdirAll(params.WorkingDirectory, os.ModePerm); err != nil {
+		return pb.NetworkPolicy_NETWORK_UNSET, executor.ExecutorError{Kind: executor.ErrCreate, Code: "workspace:create", Err: err}
+	}
+	return l.networkFor(params.Network), nil
+}
+
+// networkFor returns the policy commands run with. There is no egress proxy
+// for local processes, the allowlist falls back to no network.
+func (l *Local) networkFor(policy pb.NetworkPolicy) pb.NetworkPolicy {
+	if policy == pb.NetworkPolicy_NETWORK_FULL || !l.Namespaces || !isolated() {
+		return pb.NetworkPolicy_NETWORK_FULL
+	}
+	return pb.NetworkPolicy_NETWORK_NONE
+}
+
+func (l *Local) Exec(ctx context.Context, params executor.Params, command string, stdout io.Writer, stderr io.Writer) (int, error) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Dir = params.WorkingDirectory
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=" + params.WorkingDirectory,
+		"TMPDIR=" + os.TempDir(),
+		"LANG=C.UTF-8",
+	}
+	withLimits(cmd, params.Limits)
+	cmd.SysProcAttr = l.sysProcAttr(l.networkFor(params.Network))
+	// a process left in the background keeps the output open, stop waiting
+	// for it once the command is done
+	cmd.WaitDelay = time.Second
+
+	log.Printf("[EXECUTOR] - local exec in %s", params.WorkingDirectory)
+	if err := cmd.Start(); err != nil {
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "local:start", Err: err}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		// nothing outlives its command, as when the namespaces go
+		kill(cmd.Process.Pid)
+	case <-ctx.Done():
+		// the command runs in its own process group, everything it spawned
+		// goes with it
+		log.Printf("[EXECUTOR] - killing processes of %s", params.ContainerName)
+		kill(cmd.Process.Pid)
+		<-done
+		return 0, ctx.Err()
+	}
+
+	if errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 0, executor.ExecutorError{Kind: executor.ErrExec, Code: "local:wait", Err: err}
+	}
+	return 0, nil
+}
+
+// Teardown has nothing to release, processes do not outlive their command.
+func (l *Local) Teardown(params executor.Params) {}
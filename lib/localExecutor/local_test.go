@@ -0,0 +1,113 @@
+package localexecutor
+
+import (
+	"codexec/lib"
+	"codexec/lib/executor"
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []*pb.CodeResponse
+}
+
+func (r *recorder) Emit(event *pb.CodeResponse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func run(t *testing.T, script string, limits codexectypes.Limits) (executor.Response, *recorder, error) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "codeblock_1.sh"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	recorder := &recorder{}
+	response, err := executor.New(NewLocal(runtime.GOOS == "linux")).Run(executor.Params{
+		ContainerName:    "local",
+		WorkingDirectory: dir,
+		Limits:           limits,
+		Network:          pb.NetworkPolicy_NETWORK_NONE,
+		Events:           recorder,
+		Context:          ctx,
+		Cancel:           cancel,
+		Blocks:           []lib.SavedCodeBlock{{Path: "codeblock_1.sh", Language: "bash"}},
+	})
+	return response, recorder, err
+}
+
+func TestLocalRunsCommands(t *testing.T) {
+	response, recorder, err := run(t, "echo out; echo err >&2; exit 3\n", codexectypes.Limits{MemoryMB: 256, Pids: 64})
+
+	if err != nil {
+		t.Fatalf("a failing command is not an executor error, got %v", err)
+	}
+	if response.ExitCode != 3 || response.Stdout != "out\n" || response.Stderr != "err\n" {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	if runtime.GOOS == "linux" && response.Network != pb.NetworkPolicy_NETWORK_NONE {
+		t.Fatalf("expected no network, got %s", response.Network)
+	}
+	exited := recorder.events[len(recorder.events)-1].GetCommandExited()
+	if exited == nil || exited.ExitCode != 3 {
+		t.Fatalf("expected the exit code as last event, got %v", recorder.events)
+	}
+}
+
+func TestLocalKillsCommandOnTimeout(t *testing.T) {
+	start := time.Now()
+	response, _, err := run(t, "echo started; sleep 10 & sleep 10\n", codexectypes.Limits{CommandTimeout: 200 * time.Millisecond})
+
+	if !errors.Is(err, executor.ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if !response.TimedOut || response.ExitCode != executor.TimeoutExitCode {
+		t.Fatalf("expected a timeout, got %+v", response)
+	}
+	if !strings.Contains(response.Stdout, "started") {
+		t.Fatalf("expected the output before the timeout, got %q", response.Stdout)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command was not killed, took %s", elapsed)
+	}
+}
+
+func TestLocalLogKeepsEveryRunOfTheTask(t *testing.T) {
+	dir := t.TempDir()
+	for _, command := range []string{"echo first", "echo second"} {
+		_, err := executor.New(NewLocal(runtime.GOOS == "linux")).Run(executor.Params{
+			ContainerName:    "local",
+			WorkingDirectory: dir,
+			Network:          pb.NetworkPolicy_NETWORK_NONE,
+			Events:           &recorder{},
+			Context:          context.Background(),
+			Cancel:           func() {},
+			Commands:         []string{command},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if content, _ := os.ReadFile(executor.LogPath(dir, "local")); string(content) != "first\nsecond\n" {
+		t.Errorf("log = %q", content)
+	}
+
+	executor.ResetLog(dir, "local")
+	if content, _ := os.ReadFile(executor.LogPath(dir, "local")); len(content) != 0 {
+		t.Errorf("log after reset = %q", content)
+	}
+}
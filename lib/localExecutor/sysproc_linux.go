@@ -0,0 +1,113 @@
+//go:build linux
+
+package localexecutor
+
+import (
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// limitsEnv hands the rlimits of a command to the server started as the
+// helper running it, see withLimits.
+const limitsEnv = "CODEXEC_RLIMITS"
+
+func init() {
+	if value, ok := os.LookupEnv(limitsEnv); ok {
+		os.Exit(startLimited(value))
+	}
+}
+
+// isolated tells whether the host can run commands in new namespaces.
+func isolated() bool {
+	return true
+}
+
+func (l *Local) sysProcAttr(network pb.NetworkPolicy) *syscall.SysProcAttr {
+	attr := &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	if !l.Namespaces {
+		return attr
+	}
+
+	attr.Cloneflags = syscall.CLONE_NEWUSER | syscall.CLONE_NEWPID | syscall.CLONE_NEWNS |
+		syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC
+	if network != pb.NetworkPolicy_NETWORK_FULL {
+		attr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+	// the command keeps the ids of the server so it can write the workspace
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	attr.GidMappingsEnableSetgroups = false
+	return attr
+}
+
+// withLimits applies the task limits the kernel enforces per process to
+// cmd: MemoryMB as its address space, Pids as the processes of its user and
+// TmpfsMB as the largest file it writes. cmd then starts the server itself
+// as a helper that sets them and execs the shell, so they hold from the
+// first instruction of the command and everything it forks inherits them.
+// CPUs and CPU shares have no rlimit counterpart, and the kernel does not
+// hold a server running as root to the process limit.
+func withLimits(cmd *exec.Cmd, limits codexectypes.Limits) {
+	var rlimits []string
+	for _, rlimit := range []struct {
+		resource int
+		value    int64
+	}{
+		{unix.RLIMIT_AS, limits.MemoryMB << 20},
+		{unix.RLIMIT_NPROC, limits.Pids},
+		{unix.RLIMIT_FSIZE, limits.TmpfsMB << 20},
+	} {
+		if rlimit.value > 0 {
+			rlimits = append(rlimits, fmt.Sprintf("%d:%d", rlimit.resource, rlimit.value))
+		}
+	}
+	if len(rlimits) == 0 {
+		return
+	}
+	self, err := os.Executable()
+	if err != nil {
+		log.Printf("[EXECUTOR] running without rlimits, the server binary is not found: %v", err)
+		return
+	}
+	cmd.Path = self
+	cmd.Env = append(cmd.Env, limitsEnv+"="+strings.Join(rlimits, ","))
+}
+
+// startLimited is the helper of withLimits, it sets the rlimits in value
+// and replaces itself with the command in os.Args. It only returns the
+// exit status when it failed.
+func startLimited(value string) int {
+	for _, rlimit := range strings.Split(value, ",") {
+		var resource int
+		var limit uint64
+		if _, err := fmt.Sscanf(rlimit, "%d:%d", &resource, &limit); err != nil {
+			fmt.Fprintf(os.Stderr, "codexec: bad rlimit %q\n", rlimit)
+			return 126
+		}
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: limit, Max: limit}); err != nil {
+			fmt.Fprintf(os.Stderr, "codexec: failed to set rlimit %d: %v\n", resource, err)
+			return 126
+		}
+	}
+	var env []string
+	for _, variable := range os.Environ() {
+		if !strings.HasPrefix(variable, limitsEnv+"=") {
+			env = append(env, variable)
+		}
+	}
+	err := unix.Exec(os.Args[0], os.Args, env)
+	fmt.Fprintf(os.Stderr, "codexec: failed to run %s: %v\n", os.Args[0], err)
+	return 127
+}
+
+func kill(pid int) {
+	syscall.Kill(-pid, syscall.SIGKILL)
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package localexecutor
+
+import (
+	pb "codexec/protos/go"
+	codexectypes "codexec/types"
+	"os/exec"
+	"syscall"
+)
+
+// isolated tells whether the host can run commands in new namespaces.
+func isolated() bool {
+	return false
+}
+
+func (l *Local) sysProcAttr(network pb.NetworkPolicy) *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true}
+}
+
+// withLimits leaves cmd as it is, rlimits are only applied on Linux.
+func withLimits(cmd *exec.Cmd, limits codexectypes.Limits) {}
+
+func kill(pid int) {
+	syscall.Kill(-pid, syscall.SIGKILL)
+}
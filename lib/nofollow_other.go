@@ -0,0 +1,6 @@
+//go:build !unix
+
+package lib
+
+// noFollow is not available, WriteConfined relies on its Lstat check.
+const noFollow = 0
@@ -0,0 +1,8 @@
+//go:build unix
+
+package lib
+
+import "syscall"
+
+// noFollow makes opening a path fail when its last element is a symlink.
+const noFollow = syscall.O_NOFOLLOW
@@ -0,0 +1,441 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxFuzz is how many context lines at either end of a hunk may be left out
+// when it does not match otherwise, as `patch --fuzz=2` does.
+const maxFuzz = 2
+
+var (
+	hunkHeader    = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+\d+(?:,\d+)? @@`)
+	searchMarker  = regexp.MustCompile(`^<{5,9} SEARCH\s*$`)
+	dividerMarker = regexp.MustCompile(`^={5,9}\s*$`)
+	replaceMarker = regexp.MustCompile(`^>{5,9} REPLACE\s*$`)
+)
+
+// fileEdit is a change the model asks for to a file of the workspace.
+type fileEdit struct {
+	Path   string
+	Hunks  []hunk
+	Delete bool
+}
+
+// hunk replaces its old lines, context and removed, with its new lines,
+// context and added. Start is the 0-based line the old lines are expected
+// at, -1 when unknown as for search/replace blocks.
+type hunk struct {
+	Start int
+	Lines []hunkLine
+	// Text is the hunk the way the model wrote it, for feedback
+	Text string
+}
+
+type hunkLine struct {
+	// Op is ' ' for context, '-' for a removed and '+' for an added line
+	Op   byte
+	Text string
+}
+
+// isEdit reports whether block changes files instead of being one, a
+// unified diff or search/replace blocks. Outside a diff block a --- line
+// followed by a +++ line is only a diff header with a hunk right after it,
+// Markdown or front matter has such lines too.
+func isEdit(block CodeBlock) bool {
+	switch strings.ToLower(block.Language) {
+	case "diff", "patch", "udiff":
+		return true
+	}
+	lines := strings.Split(block.Body, "\n")
+	for i, line := range lines {
+		if searchMarker.MatchString(line) {
+			return true
+		}
+		if strings.HasPrefix(line, "--- ") && i+2 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") && strings.HasPrefix(lines[i+2], "@@ ") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEdits returns the files block changes, a unified diff is read when
+// it has no search/replace block. Files are named by the diff headers, the
+// filename of the block or the line before a SEARCH marker.
+func parseEdits(block CodeBlock) ([]fileEdit, error) {
+	if strings.Contains(block.Body, "SEARCH") {
+		for _, line := range strings.Split(block.Body, "\n") {
+			if searchMarker.MatchString(line) {
+				return parseSearchReplace(block)
+			}
+		}
+	}
+	return parseUnifiedDiff(block)
+}
+
+func parseUnifiedDiff(block CodeBlock) ([]fileEdit, error) {
+	lines := strings.Split(strings.TrimSuffix(block.Body, "\n"), "\n")
+	var edits []fileEdit
+	var edit *fileEdit
+	var current *hunk
+
+	closeHunk := func() {
+		if current != nil && edit != nil {
+			edit.Hunks = append(edit.Hunks, *current)
+		}
+		current = nil
+	}
+	startFile := func(path string, remove bool) {
+		closeHunk()
+		edits = append(edits, fileEdit{Path: path, Delete: remove})
+		edit = &edits[len(edits)-1]
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			oldPath, newPath := diffPath(line[4:]), diffPath(lines[i+1][4:])
+			if newPath == "/dev/null" {
+				startFile(oldPath, true)
+			} else {
+				startFile(newPath, false)
+			}
+			i++
+		case strings.HasPrefix(line, "@@"):
+			closeHunk()
+			if edit == nil {
+				name := block.Filename()
+				if name == "" {
+					return nil, errors.New("the diff names no file, add --- and +++ lines")
+				}
+				startFile(name, false)
+			}
+			current = &hunk{Start: -1}
+			if match := hunkHeader.FindStringSubmatch(line); match != nil {
+				start, _ := strconv.Atoi(match[1])
+				// an empty old side starts after its line rather than at it
+				if match[2] == "0" {
+					current.Start = start
+				} else if start > 0 {
+					current.Start = start - 1
+				}
+			}
+			current.Text = line + "\n"
+		case current == nil:
+			// `diff --git`, `index` and other headers
+		case strings.HasPrefix(line, `\`):
+			// \ No newline at end of file
+		default:
+			op, text := byte(' '), line
+			if line != "" && (line[0] == ' ' || line[0] == '-' || line[0] == '+') {
+				op, text = line[0], line[1:]
+			}
+			current.Lines = append(current.Lines, hunkLine{Op: op, Text: text})
+			current.Text += line + "\n"
+		}
+	}
+	closeHunk()
+
+	if len(edits) == 0 {
+		return nil, errors.New("the diff has no hunks")
+	}
+	return edits, nil
+}
+
+// diffPath returns the path of a --- or +++ line without its a/ or b/
+// prefix and timestamp.
+func diffPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		path = unquoted
+	}
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		path = path[2:]
+	}
+	return path
+}
+
+func parseSearchReplace(block CodeBlock) ([]fileEdit, error) {
+	lines := strings.Split(strings.TrimSuffix(block.Body, "\n"), "\n")
+	var edits []fileEdit
+	path := block.Filename()
+	previous := ""
+
+	for i := 0; i < len(lines); i++ {
+		if !searchMarker.MatchString(lines[i]) {
+			if line := strings.TrimSpace(lines[i]); line != "" {
+				previous = line
+			}
+			continue
+		}
+		if block.Filename() == "" && previous != "" && !strings.ContainsAny(previous, " \t") {
+			path = strings.Trim(previous, "`*:")
+		}
+		if path == "" {
+			return nil, errors.New("the SEARCH block names no file, put its path on the line before it")
+		}
+
+		current := hunk{Start: -1, Text: lines[i] + "\n"}
+		op := byte('-')
+		for i++; i < len(lines) && !replaceMarker.MatchString(lines[i]); i++ {
+			current.Text += lines[i] + "\n"
+			if op == '-' && dividerMarker.MatchString(lines[i]) {
+				op = '+'
+				continue
+			}
+			current.Lines = append(current.Lines, hunkLine{Op: op, Text: lines[i]})
+		}
+		if i == len(lines) || op == '-' {
+			return nil, fmt.Errorf("the SEARCH block for %s is not closed by ======= and >>>>>>> REPLACE", path)
+		}
+		current.Text += lines[i] + "\n"
+		previous = ""
+
+		if len(edits) > 0 && edits[len(edits)-1].Path == path {
+			edits[len(edits)-1].Hunks = append(edits[len(edits)-1].Hunks, current)
+		} else {
+			edits = append(edits, fileEdit{Path: path, Hunks: []hunk{current}})
+		}
+	}
+	return edits, nil
+}
+
+// applyEdit applies edit to its file below root. Hunks that do not match
+// are returned, the others are applied and the file written once. The new
+// content is returned, nil when the file was deleted.
+func applyEdit(root string, edit fileEdit, quota *FileQuota) ([]byte, []hunk, error) {
+	name, err := RelativePath(edit.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	name = filepath.ToSlash(name)
+
+	path := filepath.Join(root, filepath.FromSlash(name))
+	info, err := os.Lstat(path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	if exists && !info.Mode().IsRegular() {
+		return nil, nil, fmt.Errorf("path %q is not a regular file", edit.Path)
+	}
+
+	if edit.Delete {
+		if !exists {
+			return nil, nil, fmt.Errorf("%s does not exist", edit.Path)
+		}
+		if _, err := ConfinedPath(root, name); err != nil {
+			return nil, nil, err
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, nil, err
+		}
+		if quota != nil {
+			quota.remove(name)
+		}
+		return nil, nil, nil
+	}
+
+	var content []string
+	if exists {
+		resolved, err := ConfinedPath(root, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := os.ReadFile(resolved)
+		if err != nil {
+			return nil, nil, err
+		}
+		content = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(data) == 0 {
+			content = nil
+		}
+	}
+
+	var failed []hunk
+	offset := 0
+	for _, h := range edit.Hunks {
+		if !exists && h.hasOld() {
+			failed = append(failed, h)
+			continue
+		}
+		start, matched, ok := h.locate(content, offset)
+		if !ok {
+			failed = append(failed, h)
+			continue
+		}
+		n := len(matched.old())
+		replacement := matched.replace(content[start : start+n])
+		content = append(content[:start:start], append(replacement, content[start+n:]...)...)
+		// later hunks move by how far this one was off and what it added
+		if matched.Start >= 0 {
+			offset = start + len(replacement) - (matched.Start + n)
+		}
+	}
+	if len(failed) == len(edit.Hunks) {
+		if !exists {
+			return nil, failed, fmt.Errorf("%s does not exist", edit.Path)
+		}
+		return nil, failed, nil
+	}
+
+	data := []byte(strings.Join(content, "\n") + "\n")
+	if _, err := SaveFile(root, name, data, quota); err != nil {
+		return nil, nil, err
+	}
+	return data, failed, nil
+}
+
+func (h hunk) hasOld() bool {
+	for _, line := range h.Lines {
+		if line.Op != '+' {
+			return true
+		}
+	}
+	return false
+}
+
+// locate finds where the old lines of h are in content: exactly, then
+// ignoring whitespace, then leaving out up to maxFuzz context lines at
+// either end, closest to where the hunk says they are. It returns the
+// first line and the hunk that matched there.
+func (h hunk) locate(content []string, offset int) (int, hunk, bool) {
+	expected := len(content)
+	if h.Start >= 0 {
+		expected = h.Start + offset
+	}
+	if !h.hasOld() {
+		return clamp(expected, 0, len(content)), h, true
+	}
+	if h.Start < 0 {
+		expected = 0
+	}
+
+	for fuzz := 0; fuzz <= maxFuzz; fuzz++ {
+		lead, trail := h.context(fuzz)
+		if fuzz > 0 && lead == 0 && trail == 0 {
+			break
+		}
+		trimmed := h
+		trimmed.Lines = h.Lines[lead : len(h.Lines)-trail]
+		if h.Start >= 0 {
+			trimmed.Start = h.Start + lead
+		}
+		old := trimmed.old()
+		if len(old) == 0 {
+			break
+		}
+		for _, equal := range []func(a, b string) bool{exactly, ignoringSpace} {
+			if start, ok := closest(content, old, expected+lead, equal); ok {
+				return start, trimmed, true
+			}
+		}
+	}
+	return 0, h, false
+}
+
+// old returns the context and removed lines of h.
+func (h hunk) old() []string {
+	var old []string
+	for _, line := range h.Lines {
+		if line.Op != '+' {
+			old = append(old, line.Text)
+		}
+	}
+	return old
+}
+
+// context returns how many context lines to leave out at the start and the
+// end of h, at most fuzz at either end and never a removed line.
+func (h hunk) context(fuzz int) (int, int) {
+	lead := 0
+	for lead < fuzz && lead < len(h.Lines) && h.Lines[lead].Op == ' ' {
+		lead++
+	}
+	trail := 0
+	for trail < fuzz && trail < len(h.Lines)-lead && h.Lines[len(h.Lines)-1-trail].Op == ' ' {
+		trail++
+	}
+	return lead, trail
+}
+
+// replace returns the new lines of h for the old lines it matched in the
+// file, context lines are taken from the file so its formatting stays.
+func (h hunk) replace(matched []string) []string {
+	var lines []string
+	i := 0
+	for _, line := range h.Lines {
+		switch line.Op {
+		case ' ':
+			if i < len(matched) {
+				lines = append(lines, matched[i])
+			} else {
+				lines = append(lines, line.Text)
+			}
+			i++
+		case '-':
+			i++
+		case '+':
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// closest returns the start of the match of old in content nearest to
+// expected.
+func closest(content []string, old []string, expected int, equal func(a, b string) bool) (int, bool) {
+	best, found := 0, false
+	for start := 0; start+len(old) <= len(content); start++ {
+		if !matchesAt(content, old, start, equal) {
+			continue
+		}
+		if !found || distance(start, expected) < distance(best, expected) {
+			best, found = start, true
+		}
+	}
+	return best, found
+}
+
+func matchesAt(content []string, old []string, start int, equal func(a, b string) bool) bool {
+	for i, line := range old {
+		if !equal(content[start+i], line) {
+			return false
+		}
+	}
+	return true
+}
+
+func exactly(a string, b string) bool {
+	return strings.TrimRight(a, "\r") == b
+}
+
+func ignoringSpace(a string, b string) bool {
+	return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ")
+}
+
+func distance(a int, b int) int {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+func clamp(value int, min int, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditsApplyWithFuzzyContext(t *testing.T) {
+	workspace := t.TempDir()
+	os.WriteFile(filepath.Join(workspace, "app.py"), []byte("import os\n\n\ndef greet(name):\n    print('hi', name)\n\n\ndef main():\n    greet('a')\n    greet('b')\n"), 0644)
+	os.WriteFile(filepath.Join(workspace, "old.txt"), []byte("bye\n"), 0644)
+
+	// the hunk is two lines off, its context lost its indentation and its
+	// last context line is not in the file at all
+	reply := "```diff\n" +
+		"--- a/app.py\n" +
+		"+++ b/app.py\n" +
+		"@@ -2,3 +2,3 @@\n" +
+		" def greet(name):\n" +
+		"-print('hi', name)\n" +
+		"+    print('hello', name)\n" +
+		" # not in the file\n" +
+		"@@ -40,2 +40,2 @@\n" +
+		" def missing():\n" +
+		"-    pass\n" +
+		"+    return\n" +
+		"--- /dev/null\n" +
+		"+++ b/lib/util.py\n" +
+		"@@ -0,0 +1,1 @@\n" +
+		"+X = 1\n" +
+		"--- a/old.txt\n" +
+		"+++ /dev/null\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-bye\n" +
+		"```\n" +
+		"```\n" +
+		"app.py\n" +
+		"<<<<<<< SEARCH\n" +
+		"    greet('b')\n" +
+		"=======\n" +
+		"    greet('b')\n" +
+		"    greet('c')\n" +
+		">>>>>>> REPLACE\n" +
+		"```\n"
+
+	saved, rejected, err := SplitIntoCodeBlocksAndSave(reply, workspace, &FileQuota{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(workspace, "app.py"))
+	want := "import os\n\n\ndef greet(name):\n    print('hello', name)\n\n\ndef main():\n    greet('a')\n    greet('b')\n    greet('c')\n"
+	if string(content) != want {
+		t.Errorf("app.py = %q, want %q", content, want)
+	}
+	if content, _ := os.ReadFile(filepath.Join(workspace, "lib", "util.py")); string(content) != "X = 1\n" {
+		t.Errorf("lib/util.py = %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(workspace, "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("old.txt not deleted: %v", err)
+	}
+
+	var paths []string
+	for _, block := range saved {
+		paths = append(paths, block.Path)
+	}
+	if strings.Join(paths, "|") != "app.py|lib/util.py|app.py" {
+		t.Errorf("saved = %+v", saved)
+	}
+	if len(rejected) != 1 || rejected[0].Path != "app.py" || !strings.Contains(rejected[0].Hunk, "def missing():") {
+		t.Errorf("rejected = %+v", rejected)
+	}
+}
+
+func TestEditsOfMissingFilesAreRejected(t *testing.T) {
+	workspace := t.TempDir()
+	reply := "```diff\n--- a/gone.py\n+++ b/gone.py\n@@ -1 +1 @@\n-a\n+b\n```\n" +
+		"```python file=new.py\n<<<<<<< SEARCH\n=======\nprint(1)\n>>>>>>> REPLACE\n```\n"
+
+	saved, rejected, _ := SplitIntoCodeBlocksAndSave(reply, workspace, nil)
+	if len(saved) != 1 || saved[0].Path != "new.py" {
+		t.Errorf("saved = %+v", saved)
+	}
+	if content, _ := os.ReadFile(filepath.Join(workspace, "new.py")); string(content) != "print(1)\n" {
+		t.Errorf("new.py = %q", content)
+	}
+	if len(rejected) != 1 || rejected[0].Path != "gone.py" {
+		t.Errorf("rejected = %+v", rejected)
+	}
+}
+
+func TestDiffLikeLinesWithoutAHunkAreSaved(t *testing.T) {
+	workspace := t.TempDir()
+	body := "# Release notes\n\n--- draft below, not final ---\n+++ title = 'v2' +++\n\nMore to come.\n"
+	reply := "```markdown file=notes.md\n" + body + "```\n" +
+		"```\n--- a/notes.md\n+++ b/notes.md\n@@ -1 +1 @@\n-# Release notes\n+# Release notes for v2\n```\n"
+
+	saved, rejected, err := SplitIntoCodeBlocksAndSave(reply, workspace, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || len(rejected) != 0 {
+		t.Fatalf("saved = %+v, rejected = %+v", saved, rejected)
+	}
+	want := strings.Replace(body, "# Release notes\n", "# Release notes for v2\n", 1)
+	if content, _ := os.ReadFile(filepath.Join(workspace, "notes.md")); string(content) != want {
+		t.Errorf("notes.md = %q, want %q", content, want)
+	}
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfinedPath resolves the relative path name below root and fails when it
+// would point outside of it, through `..` or through a symlink.
+func ConfinedPath(root string, name string) (string, error) {
+	cleaned, err := RelativePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(resolvedRoot, cleaned)
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !within(resolvedRoot, resolved) {
+		return "", fmt.Errorf("path %q leaves the workspace", name)
+	}
+	return resolved, nil
+}
+
+func within(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// RelativePath cleans the slash separated path name and fails when it is
+// absolute or climbs out of the directory it is relative to.
+func RelativePath(name string) (string, error) {
+	if name == "" || filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("path %q must be relative", name)
+	}
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %q leaves the workspace", name)
+	}
+	return cleaned, nil
+}
+
+// WriteConfined writes content to the relative path name below root and
+// creates its missing directories. It never follows a symlink below root,
+// so a link the code of a task left in its workspace cannot redirect the
+// write to the host.
+func WriteConfined(root string, name string, content []byte, perm fs.FileMode) error {
+	cleaned, err := RelativePath(name)
+	if err != nil {
+		return err
+	}
+	if cleaned == "." {
+		return fmt.Errorf("path %q names no file", name)
+	}
+
+	dir := root
+	parts := strings.Split(cleaned, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if err := os.Mkdir(dir, os.ModePerm); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !info.IsDir():
+			return fmt.Errorf("path %q goes through %s, which is not a directory", name, part)
+		}
+	}
+
+	path := filepath.Join(root, cleaned)
+	if info, err := os.Lstat(path); err == nil && !info.Mode().IsRegular() {
+		return fmt.Errorf("path %q is not a regular file", name)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|noFollow, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
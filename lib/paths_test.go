@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveConfinesFilesToWorkspace(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	workspace := filepath.Join(root, "workspace")
+	os.MkdirAll(workspace, os.ModePerm)
+	os.Symlink(outside, filepath.Join(workspace, "link"))
+	os.Symlink(filepath.Join(outside, "target.txt"), filepath.Join(workspace, "target.txt"))
+
+	reply := "```python title=src/app/main.py\nprint(1)\n```\n" +
+		"```sh\n# filename: ../../escape.sh\necho no\n```\n" +
+		"```text file=/etc/escape\nno\n```\n" +
+		"```text file=link/escape.txt\nno\n```\n" +
+		"```text file=target.txt\nno\n```\n" +
+		"```text file=big.txt\n" + strings.Repeat("x", 64) + "\n```\n"
+
+	quota := &FileQuota{MaxFiles: 10, MaxBytes: 32}
+	saved, rejected, err := SplitIntoCodeBlocksAndSave(reply, workspace, quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Path != "src/app/main.py" {
+		t.Fatalf("saved = %+v", saved)
+	}
+	if content, err := os.ReadFile(filepath.Join(workspace, "src", "app", "main.py")); err != nil || string(content) != "print(1)\n" {
+		t.Errorf("main.py = %q, %v", content, err)
+	}
+
+	var paths []string
+	for _, file := range rejected {
+		paths = append(paths, file.Path)
+	}
+	want := []string{"../../escape.sh", "/etc/escape", "link/escape.txt", "target.txt", "big.txt"}
+	if strings.Join(paths, "|") != strings.Join(want, "|") {
+		t.Errorf("rejected = %+v, want %q", rejected, want)
+	}
+
+	entries, _ := os.ReadDir(outside)
+	if len(entries) != 0 {
+		t.Errorf("files written outside the workspace: %v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.sh")); err == nil {
+		t.Error("escape.sh written above the workspace")
+	}
+
+	// the count cap spans replies, writing a saved file again is free
+	quota.MaxFiles = 1
+	saved, rejected, _ = SplitIntoCodeBlocksAndSave("```python title=src/app/main.py\nprint(2)\n```\n```sh\nls\n```\n", workspace, quota)
+	if len(saved) != 1 || len(rejected) != 1 || rejected[0].Path != "codeblock_2.sh" {
+		t.Errorf("saved = %+v, rejected = %+v", saved, rejected)
+	}
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	pb "codexec/protos/go"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// FileStore keeps one JSON document per task in a directory. Events of a
+// running task are appended to a log next to it, one JSON line each, and
+// folded into the document once the task finishes.
+type FileStore struct {
+	mu        sync.Mutex
+	directory string
+}
+
+func NewFileStore(directory string) (*FileStore, error) {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %v", err)
+	}
+	return &FileStore{directory: directory}, nil
+}
+
+func (s *FileStore) path(id int) string {
+	return filepath.Join(s.directory, fmt.Sprintf("%d.json", id))
+}
+
+func (s *FileStore) logPath(id int) string {
+	return filepath.Join(s.directory, fmt.Sprintf("%d.events", id))
+}
+
+func (s *FileStore) Create(record *TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.write(record)
+}
+
+func (s *FileStore) Update(id int, fn func(record *TaskRecord)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.read(id)
+	if err != nil {
+		return err
+	}
+	fn(record)
+	return s.compact(record)
+}
+
+// Append writes event to the log of the task, the event ending it folds the
+// log into the record.
+func (s *FileStore) Append(id int, event *pb.CodeResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.path(id)); os.IsNotExist(err) {
+		return ErrNotFound
+	}
+	data, err := protojson.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event of task %d: %v", id, err)
+	}
+	file, err := os.OpenFile(s.logPath(id), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open events of task %d: %v", id, err)
+	}
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write event of task %d: %v", id, err)
+	}
+
+	if status := event.GetStatus(); status == pb.TaskStatus_COMPLETED || status == pb.TaskStatus_CANCELLED || status == pb.TaskStatus_FAILED {
+		record, err := s.read(id)
+		if err != nil {
+			return err
+		}
+		return s.compact(record)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(id int) (*TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read(id)
+}
+
+func (s *FileStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, path := range []string{s.path(id), s.logPath(id)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete task %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) List() ([]*TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read store directory: %v", err)
+	}
+
+	var records []*TaskRecord
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		record, err := s.read(id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// read returns the record of a task with the events of its log that are not
+// folded into it yet.
+func (s *FileStore) read(id int) (*TaskRecord, error) {
+	data, err := os.ReadFile(s.path(id))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task %d: %v", id, err)
+	}
+
+	var record TaskRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode task %d: %v", id, err)
+	}
+
+	events, err := os.ReadFile(s.logPath(id))
+	if os.IsNotExist(err) {
+		return &record, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events of task %d: %v", id, err)
+	}
+	lines := bufio.NewScanner(bytes.NewReader(events))
+	lines.Buffer(nil, 64<<20)
+	for n := 0; lines.Scan(); n++ {
+		if n < record.Events {
+			continue
+		}
+		record.Events++
+		var event pb.CodeResponse
+		// a line cut short by a crash is skipped rather than failing the record
+		if err := protojson.Unmarshal(lines.Bytes(), &event); err != nil {
+			log.Printf("[STORE] (%d) skipping unreadable event: %v", id, err)
+			continue
+		}
+		record.Apply(&event)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events of task %d: %v", id, err)
+	}
+	return &record, nil
+}
+
+// compact writes record with the events it folded in, the log goes once the
+// task is finished and nothing is appended to it anymore.
+func (s *FileStore) compact(record *TaskRecord) error {
+	if err := s.write(record); err != nil {
+		return err
+	}
+	if !record.Finished() {
+		return nil
+	}
+	if err := os.Remove(s.logPath(record.Id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete events of task %d: %v", record.Id, err)
+	}
+	return nil
+}
+
+// write replaces the record atomically so a crash never leaves half a file.
+func (s *FileStore) write(record *TaskRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode task %d: %v", record.Id, err)
+	}
+
+	tmp := s.path(record.Id) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write task %d: %v", record.Id, err)
+	}
+	return os.Rename(tmp, s.path(record.Id))
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	pb "codexec/protos/go"
+	"encoding/json"
+	"sync"
+)
+
+// MemoryStore keeps records in process, for tests and throwaway servers.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[int][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[int][]byte)}
+}
+
+func (s *MemoryStore) Create(record *TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.put(record)
+}
+
+func (s *MemoryStore) Update(id int, fn func(record *TaskRecord)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	fn(record)
+	return s.put(record)
+}
+
+func (s *MemoryStore) Append(id int, event *pb.CodeResponse) error {
+	return s.Update(id, func(record *TaskRecord) {
+		record.Apply(event)
+	})
+}
+
+func (s *MemoryStore) Get(id int) (*TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(id)
+}
+
+func (s *MemoryStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, id)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]*TaskRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []*TaskRecord
+	for id := range s.records {
+		record, err := s.get(id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// records are kept encoded so callers never share memory with the store
+func (s *MemoryStore) put(record *TaskRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	s.records[record.Id] = data
+	return nil
+}
+
+func (s *MemoryStore) get(id int) (*TaskRecord, error) {
+	data, ok := s.records[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var record TaskRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"codexec/config"
+	pb "codexec/protos/go"
+	"codexec/types"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNotFound = errors.New("task not found")
+
+// Store keeps task records beyond the lifetime of the process.
+type Store interface {
+	Create(record *TaskRecord) error
+	// Update applies fn to the stored record and saves the result.
+	Update(id int, fn func(record *TaskRecord)) error
+	// Append adds an event to the record, as folded in by Apply.
+	Append(id int, event *pb.CodeResponse) error
+	Get(id int) (*TaskRecord, error)
+	List() ([]*TaskRecord, error)
+	// Delete forgets a record, deleting one that does not exist is no error.
+	Delete(id int) error
+}
+
+type Request struct {
+	SystemPrompt string
+	UserPrompt   string
+	DockerImage  string
+	MaxRetry     int32
+	LLMProvider  string
+	LLMModel     string
+	// Seed describes where the initial workspace files came from
+	Seed    string `json:",omitempty"`
+	Limits  types.Limits
+	Network string
+}
+
+type Turn struct {
+	Round   int32
+	Role    string
+	Content string
+}
+
+type Command struct {
+	Round    int32
+	Index    int32
+	Command  string
+	ExitCode *int32 `json:",omitempty"`
+}
+
+type TaskRecord struct {
+	Id               int
+	Status           string
+	Error            string `json:",omitempty"`
+	Request          Request
+	ContainerName    string
+	WorkingDirectory string
+	Conversation     []Turn
+	Commands         []Command
+	Instrumentation  types.InstrumentationStats
+	CreatedAt        time.Time
+	StartedAt        time.Time
+	FinishedAt       time.Time
+	// Events counts the events of the file store log already folded in
+	Events int `json:",omitempty"`
+}
+
+// New returns the store selected by `store.backend` in config.toml.
+func New() (Store, error) {
+	backend := config.GetString("store.backend", "file")
+	switch backend {
+	case "file":
+		return NewFileStore(config.GetString("store.directory", "data/tasks"))
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", backend)
+	}
+}
+
+// Apply folds a task event into the record, times are those the event was
+// emitted at so replaying it later gives the same record.
+func (r *TaskRecord) Apply(event *pb.CodeResponse) {
+	at := time.Now()
+	if event.Timestamp != 0 {
+		at = time.UnixMilli(event.Timestamp)
+	}
+	round := int32(0)
+	if len(r.Conversation) > 0 {
+		round = r.Conversation[len(r.Conversation)-1].Round
+	}
+
+	switch e := event.Event.(type) {
+	case *pb.CodeResponse_Status:
+		r.Status = e.Status.String()
+		switch e.Status {
+		case pb.TaskStatus_RUNNING:
+			r.StartedAt = at
+		case pb.TaskStatus_COMPLETED, pb.TaskStatus_CANCELLED, pb.TaskStatus_FAILED:
+			r.FinishedAt = at
+		}
+	case *pb.CodeResponse_LlmMessage:
+		r.Conversation = append(r.Conversation, Turn{Round: e.LlmMessage.Round, Role: "ai", Content: e.LlmMessage.Content})
+	case *pb.CodeResponse_Feedback:
+		r.Conversation = append(r.Conversation, Turn{Round: e.Feedback.Round, Role: "human", Content: e.Feedback.Content})
+	case *pb.CodeResponse_CommandStarted:
+		r.Commands = append(r.Commands, Command{Round: round, Index: e.CommandStarted.Index, Command: e.CommandStarted.Command})
+	case *pb.CodeResponse_CommandExited:
+		for i := len(r.Commands) - 1; i >= 0; i-- {
+			if r.Commands[i].Index == e.CommandExited.Index {
+				exitCode := e.CommandExited.ExitCode
+				r.Commands[i].ExitCode = &exitCode
+				break
+			}
+		}
+	case *pb.CodeResponse_Error:
+		r.Error = e.Error.Message
+	case *pb.CodeResponse_Summary:
+		r.Instrumentation = types.InstrumentationStats{
+			LLMTokens: int(e.Summary.LlmTokens),
+			TimeTaken: e.Summary.TimeTaken,
+			Rounds:    e.Summary.Rounds,
+			Usage:     tokenUsage(e.Summary.Usage),
+			Outcome:   e.Summary.Outcome,
+			ExitCode:  int(e.Summary.ExitCode),
+			Files:     e.Summary.Files,
+			WallTime:  time.Duration(e.Summary.WallTimeMillis) * time.Millisecond,
+		}
+		for _, usage := range e.Summary.RoundUsage {
+			r.Instrumentation.RoundUsage = append(r.Instrumentation.RoundUsage, tokenUsage(usage))
+		}
+	}
+}
+
+// Persisted reports whether an event changes a task record, output and log
+// lines are only streamed.
+func Persisted(event *pb.CodeResponse) bool {
+	switch event.Event.(type) {
+	case nil, *pb.CodeResponse_Output, *pb.CodeResponse_FileExtracted, *pb.CodeResponse_Retry, *pb.CodeResponse_Terminated, *pb.CodeResponse_Seeded, *pb.CodeResponse_Image:
+		return false
+	}
+	return true
+}
+
+func (r *TaskRecord) Finished() bool {
+	return r.Status == pb.TaskStatus_COMPLETED.String() || r.Status == pb.TaskStatus_CANCELLED.String() || r.Status == pb.TaskStatus_FAILED.String()
+}
+
+func (r *TaskRecord) Info() *pb.TaskInfo {
+	return &pb.TaskInfo{
+		TaskId:      int64(r.Id),
+		Status:      pb.TaskStatus(pb.TaskStatus_value[r.Status]),
+		UserPrompt:  r.Request.UserPrompt,
+		LLMModel:    r.Request.LLMModel,
+		Provider:    r.Request.LLMProvider,
+		Outcome:     r.Instrumentation.Outcome,
+		DockerImage: r.Request.DockerImage,
+		CreatedAt:   unixMilli(r.CreatedAt),
+		StartedAt:   unixMilli(r.StartedAt),
+		FinishedAt:  unixMilli(r.FinishedAt),
+	}
+}
+
+// Recover marks tasks left queued or running by a previous process as
+// failed and hands each one to cleanup.
+func Recover(s Store, cleanup func(record *TaskRecord)) error {
+	records, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.Finished() {
+			continue
+		}
+		err := s.Update(record.Id, func(r *TaskRecord) {
+			r.Status = pb.TaskStatus_FAILED.String()
+			r.Error = "server restarted while the task was in flight"
+			r.FinishedAt = time.Now()
+		})
+		if err != nil {
+			return err
+		}
+		cleanup(record)
+	}
+	return nil
+}
+
+func tokenUsage(usage *pb.TokenUsage) types.TokenUsage {
+	return types.TokenUsage{
+		PromptTokens:     int(usage.GetPromptTokens()),
+		CompletionTokens: int(usage.GetCompletionTokens()),
+		TotalTokens:      int(usage.GetTotalTokens()),
+		EstimatedCost:    usage.GetEstimatedCost(),
+		Counted:          usage.GetCounted(),
+	}
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
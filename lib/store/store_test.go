@@ -0,0 +1,138 @@
+package store
+
+import (
+	"codexec/lib/events"
+	pb "codexec/protos/go"
+	"codexec/types"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// run emits the events of a task that ran one command and finished.
+func run(t *testing.T, s Store, id int, finish bool) {
+	t.Helper()
+	emitted := []*pb.CodeResponse{
+		events.Status(pb.TaskStatus_RUNNING),
+		events.LLMMessage(0, "```sh\necho hi\n```"),
+		events.CommandStarted(0, "sh codeblock_1.sh"),
+		events.CommandExited(0, 3),
+		events.Feedback(1, "exit_code - 3"),
+		events.Error("llm:generate", "rate limited"),
+		events.Summary(types.InstrumentationStats{Rounds: 1, Outcome: pb.Outcome_OUTCOME_ERROR}),
+	}
+	if finish {
+		emitted = append(emitted, events.Status(pb.TaskStatus_FAILED))
+	}
+	for i, event := range emitted {
+		event.Timestamp = time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC).UnixMilli()
+		if err := s.Append(id, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	s := NewMemoryStore()
+	s.Create(&TaskRecord{Id: 1, Status: pb.TaskStatus_QUEUED.String()})
+	run(t, s, 1, true)
+
+	record, err := s.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != "FAILED" || record.Error != "rate limited" || record.Instrumentation.Outcome != pb.Outcome_OUTCOME_ERROR {
+		t.Errorf("record = %+v", record)
+	}
+	if len(record.Conversation) != 2 || record.Conversation[0].Role != "ai" || record.Conversation[1].Role != "human" {
+		t.Errorf("conversation = %+v", record.Conversation)
+	}
+	if len(record.Commands) != 1 || record.Commands[0].ExitCode == nil || *record.Commands[0].ExitCode != 3 {
+		t.Errorf("commands = %+v", record.Commands)
+	}
+	if !record.StartedAt.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !record.FinishedAt.Equal(time.Date(2024, 1, 1, 0, 0, 7, 0, time.UTC)) {
+		t.Errorf("started %s, finished %s, want the times of the events", record.StartedAt, record.FinishedAt)
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	directory := t.TempDir()
+	s, err := NewFileStore(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{1, 2} {
+		if err := s.Create(&TaskRecord{Id: id, Status: "QUEUED", Request: Request{UserPrompt: "hi"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run(t, s, 1, true)
+	run(t, s, 2, false)
+	if err := s.Append(3, events.Status(pb.TaskStatus_RUNNING)); err != ErrNotFound {
+		t.Errorf("appending to a missing task = %v, want ErrNotFound", err)
+	}
+
+	// a finished task is folded into its record, a running one keeps its log
+	if _, err := os.Stat(filepath.Join(directory, "1.events")); !os.IsNotExist(err) {
+		t.Errorf("log of the finished task left: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(directory, "2.events")); err != nil {
+		t.Errorf("log of the running task: %v", err)
+	}
+
+	reopened, _ := NewFileStore(directory)
+	records, err := reopened.List()
+	if err != nil || len(records) != 2 {
+		t.Fatalf("List = %d records, %v", len(records), err)
+	}
+	for _, record := range records {
+		want := map[int]string{1: "FAILED", 2: "RUNNING"}[record.Id]
+		if record.Status != want || record.Request.UserPrompt != "hi" || len(record.Conversation) != 2 || len(record.Commands) != 1 {
+			t.Errorf("record %d = %+v", record.Id, record)
+		}
+	}
+
+	// the running task keeps going after a compaction of its log
+	if err := reopened.Update(2, func(record *TaskRecord) { record.Error = "noted" }); err != nil {
+		t.Fatal(err)
+	}
+	reopened.Append(2, events.Feedback(2, "again"))
+	record, _ := reopened.Get(2)
+	if record.Error != "noted" || len(record.Conversation) != 3 {
+		t.Errorf("record after update = %+v", record)
+	}
+
+	if err := reopened.Delete(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reopened.Get(2); err != ErrNotFound {
+		t.Errorf("Get of a deleted task = %v", err)
+	}
+}
+
+func TestRecoverFailsTasksInFlight(t *testing.T) {
+	s, _ := NewFileStore(t.TempDir())
+	s.Create(&TaskRecord{Id: 1, Status: "QUEUED"})
+	s.Create(&TaskRecord{Id: 2, Status: "QUEUED"})
+	s.Create(&TaskRecord{Id: 3, Status: "COMPLETED"})
+	run(t, s, 2, false)
+
+	var cleaned []int
+	if err := Recover(s, func(record *TaskRecord) { cleaned = append(cleaned, record.Id) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cleaned) != 2 {
+		t.Errorf("cleaned up %v, want the two tasks in flight", cleaned)
+	}
+	for id, want := range map[int]string{1: "FAILED", 2: "FAILED", 3: "COMPLETED"} {
+		record, _ := s.Get(id)
+		if record.Status != want || (want == "FAILED" && (record.FinishedAt.IsZero() || record.Error == "")) {
+			t.Errorf("record %d = %+v", id, record)
+		}
+	}
+	if record, _ := s.Get(2); len(record.Commands) != 1 {
+		t.Errorf("events of the recovered task lost: %+v", record)
+	}
+}
@@ -1,6 +1,13 @@
 package lib
 
-import "math/rand"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"path/filepath"
+)
 
 func GetContainerName(n int) string {
 	const letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
@@ -10,3 +17,49 @@ func GetContainerName(n int) string {
 	}
 	return string(b)
 }
+
+// ListFiles returns the files below dir as slash separated paths relative to it.
+func ListFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// CountFiles returns how many regular files are below dir and their total size.
+func CountFiles(dir string) (int, int64, error) {
+	var files int
+	var size int64
+	err := walkRegularFiles(dir, func(path string, name string, info fs.FileInfo) error {
+		files++
+		size += info.Size()
+		return nil
+	})
+	return files, size, err
+}
+
+// HashDirectory returns a hex digest of the names, modes and contents of the
+// regular files below dir, it changes whenever one of them does.
+func HashDirectory(dir string) (string, error) {
+	hash := sha256.New()
+	err := walkRegularFiles(dir, func(path string, name string, info fs.FileInfo) error {
+		fmt.Fprintf(hash, "%s\x00%o\x00%d\x00", name, info.Mode().Perm(), info.Size())
+		return copyFile(hash, path)
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	pb "codexec/protos/go"
+	"codexec/types"
 	"log"
 )
 
@@ -11,16 +12,17 @@ type StreamWriter struct {
 	stream pb.StreamService_StreamDataServer // The gRPC stream
 }
 
-type CodeStreamWriter struct {
-	stream pb.CoderService_ExecuteCodeServer // The gRPC stream
+// EventWriter turns log lines into text events of a task event stream.
+type EventWriter struct {
+	events types.EventEmitter
 }
 
 func NewStreamWriter(stream pb.StreamService_StreamDataServer) *StreamWriter {
 	return &StreamWriter{stream: stream}
 }
 
-func NewCodeStreamWriter(stream pb.CoderService_ExecuteCodeServer) *CodeStreamWriter {
-	return &CodeStreamWriter{stream: stream}
+func NewEventWriter(events types.EventEmitter) *EventWriter {
+	return &EventWriter{events: events}
 }
 
 func (w *StreamWriter) Write(p []byte) (n int, err error) {
@@ -35,15 +37,9 @@ func (w *StreamWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func (w *CodeStreamWriter) Write(p []byte) (n int, err error) {
-	message := string(p)
-	err = w.stream.Send(&pb.CodeResponse{
-		Data: message,
+func (w *EventWriter) Write(p []byte) (n int, err error) {
+	w.events.Emit(&pb.CodeResponse{
+		Data: string(p),
 	})
-
-	if err != nil {
-		return 0, err
-	}
-
 	return len(p), nil
 }
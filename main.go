@@ -5,7 +5,6 @@ import (
 	"codexec/config"
 	"codexec/rpc"
 	"flag"
-	"fmt"
 	"log"
 	"time"
 
@@ -34,7 +33,6 @@ func main() {
 	forked := flag.Bool("background-forked", false, "Internal flag to indicate background mode")
 	viewLog := flag.Bool("logs", false, "View logs from the log file in real-time (like tail -f)")
 	flag.Parse()
-	fmt.Sprintln(background, forked, viewLog)
 
 	if *viewLog {
 		if err := cli.TailLogs(); err != nil {
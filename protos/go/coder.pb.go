@@ -20,17 +20,389 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// AgentMode is how the model drives a task.
+type AgentMode int32
+
+const (
+	// the model replies in markdown, its code blocks are saved and run and
+	// the output sent back until it replies TERMINATE
+	AgentMode_AGENT_MODE_CODE_BLOCKS AgentMode = 0
+	// the model calls write_file, read_file, list_dir, run_command and
+	// finish tools through the native tool calling of its provider, maxRetry
+	// caps its replies, `agent.maxToolRounds` of config.toml when unset
+	AgentMode_AGENT_MODE_TOOLS AgentMode = 1
+)
+
+// Enum value maps for AgentMode.
+var (
+	AgentMode_name = map[int32]string{
+		0: "AGENT_MODE_CODE_BLOCKS",
+		1: "AGENT_MODE_TOOLS",
+	}
+	AgentMode_value = map[string]int32{
+		"AGENT_MODE_CODE_BLOCKS": 0,
+		"AGENT_MODE_TOOLS":       1,
+	}
+)
+
+func (x AgentMode) Enum() *AgentMode {
+	p := new(AgentMode)
+	*p = x
+	return p
+}
+
+func (x AgentMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AgentMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[0].Descriptor()
+}
+
+func (AgentMode) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[0]
+}
+
+func (x AgentMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AgentMode.Descriptor instead.
+func (AgentMode) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{0}
+}
+
+// NetworkPolicy is ordered from closed to open, config.toml caps what a
+// request may ask for with `network.maxPolicy`.
+type NetworkPolicy int32
+
+const (
+	NetworkPolicy_NETWORK_UNSET NetworkPolicy = 0
+	// no network interface besides loopback
+	NetworkPolicy_NETWORK_NONE NetworkPolicy = 1
+	// only package mirrors on `network.allowlist`, through the egress proxy
+	NetworkPolicy_NETWORK_ALLOWLIST NetworkPolicy = 2
+	NetworkPolicy_NETWORK_FULL      NetworkPolicy = 3
+)
+
+// Enum value maps for NetworkPolicy.
+var (
+	NetworkPolicy_name = map[int32]string{
+		0: "NETWORK_UNSET",
+		1: "NETWORK_NONE",
+		2: "NETWORK_ALLOWLIST",
+		3: "NETWORK_FULL",
+	}
+	NetworkPolicy_value = map[string]int32{
+		"NETWORK_UNSET":     0,
+		"NETWORK_NONE":      1,
+		"NETWORK_ALLOWLIST": 2,
+		"NETWORK_FULL":      3,
+	}
+)
+
+func (x NetworkPolicy) Enum() *NetworkPolicy {
+	p := new(NetworkPolicy)
+	*p = x
+	return p
+}
+
+func (x NetworkPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NetworkPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[1].Descriptor()
+}
+
+func (NetworkPolicy) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[1]
+}
+
+func (x NetworkPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NetworkPolicy.Descriptor instead.
+func (NetworkPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{1}
+}
+
+type TaskStatus int32
+
+const (
+	TaskStatus_QUEUED    TaskStatus = 0
+	TaskStatus_RUNNING   TaskStatus = 1
+	TaskStatus_COMPLETED TaskStatus = 2
+	TaskStatus_CANCELLED TaskStatus = 3
+	TaskStatus_FAILED    TaskStatus = 4
+)
+
+// Enum value maps for TaskStatus.
+var (
+	TaskStatus_name = map[int32]string{
+		0: "QUEUED",
+		1: "RUNNING",
+		2: "COMPLETED",
+		3: "CANCELLED",
+		4: "FAILED",
+	}
+	TaskStatus_value = map[string]int32{
+		"QUEUED":    0,
+		"RUNNING":   1,
+		"COMPLETED": 2,
+		"CANCELLED": 3,
+		"FAILED":    4,
+	}
+)
+
+func (x TaskStatus) Enum() *TaskStatus {
+	p := new(TaskStatus)
+	*p = x
+	return p
+}
+
+func (x TaskStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TaskStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[2].Descriptor()
+}
+
+func (TaskStatus) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[2]
+}
+
+func (x TaskStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TaskStatus.Descriptor instead.
+func (TaskStatus) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{2}
+}
+
+type OutputStream int32
+
+const (
+	OutputStream_STDOUT OutputStream = 0
+	OutputStream_STDERR OutputStream = 1
+)
+
+// Enum value maps for OutputStream.
+var (
+	OutputStream_name = map[int32]string{
+		0: "STDOUT",
+		1: "STDERR",
+	}
+	OutputStream_value = map[string]int32{
+		"STDOUT": 0,
+		"STDERR": 1,
+	}
+)
+
+func (x OutputStream) Enum() *OutputStream {
+	p := new(OutputStream)
+	*p = x
+	return p
+}
+
+func (x OutputStream) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OutputStream) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[3].Descriptor()
+}
+
+func (OutputStream) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[3]
+}
+
+func (x OutputStream) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OutputStream.Descriptor instead.
+func (OutputStream) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{3}
+}
+
+type ImageAction int32
+
+const (
+	ImageAction_IMAGE_PULL ImageAction = 0
+	// built from its directory under `images.buildpacks`
+	ImageAction_IMAGE_BUILD ImageAction = 1
+)
+
+// Enum value maps for ImageAction.
+var (
+	ImageAction_name = map[int32]string{
+		0: "IMAGE_PULL",
+		1: "IMAGE_BUILD",
+	}
+	ImageAction_value = map[string]int32{
+		"IMAGE_PULL":  0,
+		"IMAGE_BUILD": 1,
+	}
+)
+
+func (x ImageAction) Enum() *ImageAction {
+	p := new(ImageAction)
+	*p = x
+	return p
+}
+
+func (x ImageAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImageAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[4].Descriptor()
+}
+
+func (ImageAction) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[4]
+}
+
+func (x ImageAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImageAction.Descriptor instead.
+func (ImageAction) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{4}
+}
+
+type Outcome int32
+
+const (
+	Outcome_OUTCOME_UNKNOWN Outcome = 0
+	// the model replied TERMINATE
+	Outcome_OUTCOME_TERMINATED  Outcome = 1
+	Outcome_OUTCOME_MAX_RETRIES Outcome = 2
+	Outcome_OUTCOME_CANCELLED   Outcome = 3
+	Outcome_OUTCOME_ERROR       Outcome = 4
+	// the task ran past its timeout
+	Outcome_OUTCOME_TIMED_OUT Outcome = 5
+)
+
+// Enum value maps for Outcome.
+var (
+	Outcome_name = map[int32]string{
+		0: "OUTCOME_UNKNOWN",
+		1: "OUTCOME_TERMINATED",
+		2: "OUTCOME_MAX_RETRIES",
+		3: "OUTCOME_CANCELLED",
+		4: "OUTCOME_ERROR",
+		5: "OUTCOME_TIMED_OUT",
+	}
+	Outcome_value = map[string]int32{
+		"OUTCOME_UNKNOWN":     0,
+		"OUTCOME_TERMINATED":  1,
+		"OUTCOME_MAX_RETRIES": 2,
+		"OUTCOME_CANCELLED":   3,
+		"OUTCOME_ERROR":       4,
+		"OUTCOME_TIMED_OUT":   5,
+	}
+)
+
+func (x Outcome) Enum() *Outcome {
+	p := new(Outcome)
+	*p = x
+	return p
+}
+
+func (x Outcome) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Outcome) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[5].Descriptor()
+}
+
+func (Outcome) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[5]
+}
+
+func (x Outcome) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Outcome.Descriptor instead.
+func (Outcome) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{5}
+}
+
+type ArchiveFormat int32
+
+const (
+	ArchiveFormat_TAR_GZ ArchiveFormat = 0
+	ArchiveFormat_ZIP    ArchiveFormat = 1
+)
+
+// Enum value maps for ArchiveFormat.
+var (
+	ArchiveFormat_name = map[int32]string{
+		0: "TAR_GZ",
+		1: "ZIP",
+	}
+	ArchiveFormat_value = map[string]int32{
+		"TAR_GZ": 0,
+		"ZIP":    1,
+	}
+)
+
+func (x ArchiveFormat) Enum() *ArchiveFormat {
+	p := new(ArchiveFormat)
+	*p = x
+	return p
+}
+
+func (x ArchiveFormat) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ArchiveFormat) Descriptor() protoreflect.EnumDescriptor {
+	return file_protos_coder_proto_enumTypes[6].Descriptor()
+}
+
+func (ArchiveFormat) Type() protoreflect.EnumType {
+	return &file_protos_coder_proto_enumTypes[6]
+}
+
+func (x ArchiveFormat) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ArchiveFormat.Descriptor instead.
+func (ArchiveFormat) EnumDescriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{6}
+}
+
 type CodeRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SystemPrompt     string `protobuf:"bytes,1,opt,name=systemPrompt,proto3" json:"systemPrompt,omitempty"`
-	UserPrompt       string `protobuf:"bytes,2,opt,name=userPrompt,proto3" json:"userPrompt,omitempty"`
+	SystemPrompt string `protobuf:"bytes,1,opt,name=systemPrompt,proto3" json:"systemPrompt,omitempty"`
+	UserPrompt   string `protobuf:"bytes,2,opt,name=userPrompt,proto3" json:"userPrompt,omitempty"`
+	// ignored, the server picks the workspace of a task, use seed to fill it
 	WorkingDirectory string `protobuf:"bytes,3,opt,name=workingDirectory,proto3" json:"workingDirectory,omitempty"`
 	DockerImage      string `protobuf:"bytes,4,opt,name=dockerImage,proto3" json:"dockerImage,omitempty"`
 	MaxRetry         int32  `protobuf:"varint,5,opt,name=maxRetry,proto3" json:"maxRetry,omitempty"`
 	LLMModel         string `protobuf:"bytes,6,opt,name=LLMModel,proto3" json:"LLMModel,omitempty"`
+	// name of a provider in the [llm.providers] section of config.toml
+	Provider string `protobuf:"bytes,7,opt,name=provider,proto3" json:"provider,omitempty"`
+	// files placed in the workspace before the first round
+	Seed *Seed `protobuf:"bytes,8,opt,name=seed,proto3" json:"seed,omitempty"`
+	// resources of the task, unset fields take the [limits] of config.toml
+	Limits *Limits `protobuf:"bytes,9,opt,name=limits,proto3" json:"limits,omitempty"`
+	// network access of the code, unset takes `network.policy` of config.toml
+	Network NetworkPolicy `protobuf:"varint,10,opt,name=network,proto3,enum=coder.NetworkPolicy" json:"network,omitempty"`
+	Mode    AgentMode     `protobuf:"varint,11,opt,name=mode,proto3,enum=coder.AgentMode" json:"mode,omitempty"`
 }
 
 func (x *CodeRequest) Reset() {
@@ -105,28 +477,74 @@ func (x *CodeRequest) GetLLMModel() string {
 	return ""
 }
 
-type CodeResponse struct {
+func (x *CodeRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *CodeRequest) GetSeed() *Seed {
+	if x != nil {
+		return x.Seed
+	}
+	return nil
+}
+
+func (x *CodeRequest) GetLimits() *Limits {
+	if x != nil {
+		return x.Limits
+	}
+	return nil
+}
+
+func (x *CodeRequest) GetNetwork() NetworkPolicy {
+	if x != nil {
+		return x.Network
+	}
+	return NetworkPolicy_NETWORK_UNSET
+}
+
+func (x *CodeRequest) GetMode() AgentMode {
+	if x != nil {
+		return x.Mode
+	}
+	return AgentMode_AGENT_MODE_CODE_BLOCKS
+}
+
+// Limits bound what the code of a task may use. A request can lower the
+// configured limits but not raise them.
+type Limits struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Data string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// CPU cores, fractions allowed
+	Cpus float64 `protobuf:"fixed64,1,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	// relative CPU weight against other tasks, 1024 is the docker default
+	CpuShares int64 `protobuf:"varint,2,opt,name=cpuShares,proto3" json:"cpuShares,omitempty"`
+	MemoryMB  int64 `protobuf:"varint,3,opt,name=memoryMB,proto3" json:"memoryMB,omitempty"`
+	Pids      int64 `protobuf:"varint,4,opt,name=pids,proto3" json:"pids,omitempty"`
+	// size of the tmpfs mounted at /tmp
+	TmpfsMB               int64 `protobuf:"varint,5,opt,name=tmpfsMB,proto3" json:"tmpfsMB,omitempty"`
+	CommandTimeoutSeconds int32 `protobuf:"varint,6,opt,name=commandTimeoutSeconds,proto3" json:"commandTimeoutSeconds,omitempty"`
+	TaskTimeoutSeconds    int32 `protobuf:"varint,7,opt,name=taskTimeoutSeconds,proto3" json:"taskTimeoutSeconds,omitempty"`
 }
 
-func (x *CodeResponse) Reset() {
-	*x = CodeResponse{}
+func (x *Limits) Reset() {
+	*x = Limits{}
 	mi := &file_protos_coder_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CodeResponse) String() string {
+func (x *Limits) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CodeResponse) ProtoMessage() {}
+func (*Limits) ProtoMessage() {}
 
-func (x *CodeResponse) ProtoReflect() protoreflect.Message {
+func (x *Limits) ProtoReflect() protoreflect.Message {
 	mi := &file_protos_coder_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -138,91 +556,2549 @@ func (x *CodeResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CodeResponse.ProtoReflect.Descriptor instead.
-func (*CodeResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use Limits.ProtoReflect.Descriptor instead.
+func (*Limits) Descriptor() ([]byte, []int) {
 	return file_protos_coder_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *CodeResponse) GetData() string {
+func (x *Limits) GetCpus() float64 {
 	if x != nil {
-		return x.Data
+		return x.Cpus
 	}
-	return ""
+	return 0
 }
 
-var File_protos_coder_proto protoreflect.FileDescriptor
+func (x *Limits) GetCpuShares() int64 {
+	if x != nil {
+		return x.CpuShares
+	}
+	return 0
+}
 
-var file_protos_coder_proto_rawDesc = []byte{
-	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x22, 0xd7, 0x01, 0x0a, 0x0b,
-	0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x73,
-	0x79, 0x73, 0x74, 0x65, 0x6d, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0c, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12,
-	0x1e, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12,
-	0x2a, 0x0a, 0x10, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
-	0x6f, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x77, 0x6f, 0x72, 0x6b, 0x69,
-	0x6e, 0x67, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x64,
-	0x6f, 0x63, 0x6b, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0b, 0x64, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a,
-	0x08, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x08, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x4c, 0x4d,
-	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x4c, 0x4c, 0x4d,
-	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x22, 0x22, 0x0a, 0x0c, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x32, 0x48, 0x0a, 0x0c, 0x43, 0x6f, 0x64,
-	0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x38, 0x0a, 0x0b, 0x45, 0x78, 0x65,
-	0x63, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72,
-	0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x63,
-	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x30, 0x01, 0x42, 0x0d, 0x5a, 0x0b, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f,
-	0x67, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *Limits) GetMemoryMB() int64 {
+	if x != nil {
+		return x.MemoryMB
+	}
+	return 0
 }
 
-var (
-	file_protos_coder_proto_rawDescOnce sync.Once
-	file_protos_coder_proto_rawDescData = file_protos_coder_proto_rawDesc
-)
+func (x *Limits) GetPids() int64 {
+	if x != nil {
+		return x.Pids
+	}
+	return 0
+}
 
-func file_protos_coder_proto_rawDescGZIP() []byte {
-	file_protos_coder_proto_rawDescOnce.Do(func() {
-		file_protos_coder_proto_rawDescData = protoimpl.X.CompressGZIP(file_protos_coder_proto_rawDescData)
-	})
-	return file_protos_coder_proto_rawDescData
+func (x *Limits) GetTmpfsMB() int64 {
+	if x != nil {
+		return x.TmpfsMB
+	}
+	return 0
 }
 
-var file_protos_coder_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_protos_coder_proto_goTypes = []any{
-	(*CodeRequest)(nil),  // 0: coder.CodeRequest
-	(*CodeResponse)(nil), // 1: coder.CodeResponse
+func (x *Limits) GetCommandTimeoutSeconds() int32 {
+	if x != nil {
+		return x.CommandTimeoutSeconds
+	}
+	return 0
 }
-var file_protos_coder_proto_depIdxs = []int32{
-	0, // 0: coder.CoderService.ExecuteCode:input_type -> coder.CodeRequest
-	1, // 1: coder.CoderService.ExecuteCode:output_type -> coder.CodeResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *Limits) GetTaskTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TaskTimeoutSeconds
+	}
+	return 0
 }
 
-func init() { file_protos_coder_proto_init() }
-func file_protos_coder_proto_init() {
-	if File_protos_coder_proto != nil {
-		return
+// Seed pre-populates a task workspace, either from an archive sent with the
+// request or from a bare git repository on the server.
+type Seed struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Source:
+	//	*Seed_Archive
+	//	*Seed_GitRepository
+	Source        isSeed_Source `protobuf_oneof:"source"`
+	ArchiveFormat ArchiveFormat `protobuf:"varint,3,opt,name=archiveFormat,proto3,enum=coder.ArchiveFormat" json:"archiveFormat,omitempty"`
+	// branch or tag to check out, the repository HEAD when empty
+	GitRef string `protobuf:"bytes,4,opt,name=gitRef,proto3" json:"gitRef,omitempty"`
+}
+
+func (x *Seed) Reset() {
+	*x = Seed{}
+	mi := &file_protos_coder_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Seed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Seed) ProtoMessage() {}
+
+func (x *Seed) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Seed.ProtoReflect.Descriptor instead.
+func (*Seed) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{2}
+}
+
+func (m *Seed) GetSource() isSeed_Source {
+	if m != nil {
+		return m.Source
+	}
+	return nil
+}
+
+func (x *Seed) GetArchive() []byte {
+	if x, ok := x.GetSource().(*Seed_Archive); ok {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *Seed) GetGitRepository() string {
+	if x, ok := x.GetSource().(*Seed_GitRepository); ok {
+		return x.GitRepository
+	}
+	return ""
+}
+
+func (x *Seed) GetArchiveFormat() ArchiveFormat {
+	if x != nil {
+		return x.ArchiveFormat
+	}
+	return ArchiveFormat_TAR_GZ
+}
+
+func (x *Seed) GetGitRef() string {
+	if x != nil {
+		return x.GitRef
+	}
+	return ""
+}
+
+type isSeed_Source interface {
+	isSeed_Source()
+}
+
+type Seed_Archive struct {
+	Archive []byte `protobuf:"bytes,1,opt,name=archive,proto3,oneof"`
+}
+
+type Seed_GitRepository struct {
+	// path of a bare repository below `workspace.gitRoot` in config.toml
+	GitRepository string `protobuf:"bytes,2,opt,name=gitRepository,proto3,oneof"`
+}
+
+func (*Seed_Archive) isSeed_Source() {}
+
+func (*Seed_GitRepository) isSeed_Source() {}
+
+// CodeResponse is one entry of the task event stream. `data` carries the
+// human readable log line, `event` the typed payload clients can render.
+type CodeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data      string `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	TaskId    int64  `protobuf:"varint,2,opt,name=taskId,proto3" json:"taskId,omitempty"`
+	Timestamp int64  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Types that are assignable to Event:
+	//	*CodeResponse_LlmMessage
+	//	*CodeResponse_FileExtracted
+	//	*CodeResponse_CommandStarted
+	//	*CodeResponse_Output
+	//	*CodeResponse_CommandExited
+	//	*CodeResponse_Retry
+	//	*CodeResponse_Terminated
+	//	*CodeResponse_Error
+	//	*CodeResponse_Summary
+	//	*CodeResponse_Status
+	//	*CodeResponse_Feedback
+	//	*CodeResponse_Seeded
+	//	*CodeResponse_Image
+	//	*CodeResponse_FileRejected
+	//	*CodeResponse_ToolCall
+	//	*CodeResponse_ToolResult
+	Event isCodeResponse_Event `protobuf_oneof:"event"`
+}
+
+func (x *CodeResponse) Reset() {
+	*x = CodeResponse{}
+	mi := &file_protos_coder_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodeResponse) ProtoMessage() {}
+
+func (x *CodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodeResponse.ProtoReflect.Descriptor instead.
+func (*CodeResponse) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CodeResponse) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *CodeResponse) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *CodeResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (m *CodeResponse) GetEvent() isCodeResponse_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetLlmMessage() *LLMMessage {
+	if x, ok := x.GetEvent().(*CodeResponse_LlmMessage); ok {
+		return x.LlmMessage
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetFileExtracted() *FileExtracted {
+	if x, ok := x.GetEvent().(*CodeResponse_FileExtracted); ok {
+		return x.FileExtracted
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetCommandStarted() *CommandStarted {
+	if x, ok := x.GetEvent().(*CodeResponse_CommandStarted); ok {
+		return x.CommandStarted
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetOutput() *OutputChunk {
+	if x, ok := x.GetEvent().(*CodeResponse_Output); ok {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetCommandExited() *CommandExited {
+	if x, ok := x.GetEvent().(*CodeResponse_CommandExited); ok {
+		return x.CommandExited
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetRetry() *Retry {
+	if x, ok := x.GetEvent().(*CodeResponse_Retry); ok {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetTerminated() *Terminated {
+	if x, ok := x.GetEvent().(*CodeResponse_Terminated); ok {
+		return x.Terminated
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetError() *Error {
+	if x, ok := x.GetEvent().(*CodeResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetSummary() *Summary {
+	if x, ok := x.GetEvent().(*CodeResponse_Summary); ok {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetStatus() TaskStatus {
+	if x, ok := x.GetEvent().(*CodeResponse_Status); ok {
+		return x.Status
+	}
+	return TaskStatus_QUEUED
+}
+
+func (x *CodeResponse) GetFeedback() *Feedback {
+	if x, ok := x.GetEvent().(*CodeResponse_Feedback); ok {
+		return x.Feedback
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetSeeded() *WorkspaceSeeded {
+	if x, ok := x.GetEvent().(*CodeResponse_Seeded); ok {
+		return x.Seeded
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetImage() *ImageProgress {
+	if x, ok := x.GetEvent().(*CodeResponse_Image); ok {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetFileRejected() *FileRejected {
+	if x, ok := x.GetEvent().(*CodeResponse_FileRejected); ok {
+		return x.FileRejected
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetToolCall() *ToolCall {
+	if x, ok := x.GetEvent().(*CodeResponse_ToolCall); ok {
+		return x.ToolCall
+	}
+	return nil
+}
+
+func (x *CodeResponse) GetToolResult() *ToolResult {
+	if x, ok := x.GetEvent().(*CodeResponse_ToolResult); ok {
+		return x.ToolResult
+	}
+	return nil
+}
+
+type isCodeResponse_Event interface {
+	isCodeResponse_Event()
+}
+
+type CodeResponse_LlmMessage struct {
+	LlmMessage *LLMMessage `protobuf:"bytes,10,opt,name=llmMessage,proto3,oneof"`
+}
+
+type CodeResponse_FileExtracted struct {
+	FileExtracted *FileExtracted `protobuf:"bytes,11,opt,name=fileExtracted,proto3,oneof"`
+}
+
+type CodeResponse_CommandStarted struct {
+	CommandStarted *CommandStarted `protobuf:"bytes,12,opt,name=commandStarted,proto3,oneof"`
+}
+
+type CodeResponse_Output struct {
+	Output *OutputChunk `protobuf:"bytes,13,opt,name=output,proto3,oneof"`
+}
+
+type CodeResponse_CommandExited struct {
+	CommandExited *CommandExited `protobuf:"bytes,14,opt,name=commandExited,proto3,oneof"`
+}
+
+type CodeResponse_Retry struct {
+	Retry *Retry `protobuf:"bytes,15,opt,name=retry,proto3,oneof"`
+}
+
+type CodeResponse_Terminated struct {
+	Terminated *Terminated `protobuf:"bytes,16,opt,name=terminated,proto3,oneof"`
+}
+
+type CodeResponse_Error struct {
+	Error *Error `protobuf:"bytes,17,opt,name=error,proto3,oneof"`
+}
+
+type CodeResponse_Summary struct {
+	Summary *Summary `protobuf:"bytes,18,opt,name=summary,proto3,oneof"`
+}
+
+type CodeResponse_Status struct {
+	Status TaskStatus `protobuf:"varint,19,opt,name=status,proto3,enum=coder.TaskStatus,oneof"`
+}
+
+type CodeResponse_Feedback struct {
+	Feedback *Feedback `protobuf:"bytes,20,opt,name=feedback,proto3,oneof"`
+}
+
+type CodeResponse_Seeded struct {
+	Seeded *WorkspaceSeeded `protobuf:"bytes,21,opt,name=seeded,proto3,oneof"`
+}
+
+type CodeResponse_Image struct {
+	Image *ImageProgress `protobuf:"bytes,22,opt,name=image,proto3,oneof"`
+}
+
+type CodeResponse_FileRejected struct {
+	FileRejected *FileRejected `protobuf:"bytes,23,opt,name=fileRejected,proto3,oneof"`
+}
+
+type CodeResponse_ToolCall struct {
+	ToolCall *ToolCall `protobuf:"bytes,24,opt,name=toolCall,proto3,oneof"`
+}
+
+type CodeResponse_ToolResult struct {
+	ToolResult *ToolResult `protobuf:"bytes,25,opt,name=toolResult,proto3,oneof"`
+}
+
+func (*CodeResponse_LlmMessage) isCodeResponse_Event() {}
+
+func (*CodeResponse_FileExtracted) isCodeResponse_Event() {}
+
+func (*CodeResponse_CommandStarted) isCodeResponse_Event() {}
+
+func (*CodeResponse_Output) isCodeResponse_Event() {}
+
+func (*CodeResponse_CommandExited) isCodeResponse_Event() {}
+
+func (*CodeResponse_Retry) isCodeResponse_Event() {}
+
+func (*CodeResponse_Terminated) isCodeResponse_Event() {}
+
+func (*CodeResponse_Error) isCodeResponse_Event() {}
+
+func (*CodeResponse_Summary) isCodeResponse_Event() {}
+
+func (*CodeResponse_Status) isCodeResponse_Event() {}
+
+func (*CodeResponse_Feedback) isCodeResponse_Event() {}
+
+func (*CodeResponse_Seeded) isCodeResponse_Event() {}
+
+func (*CodeResponse_Image) isCodeResponse_Event() {}
+
+func (*CodeResponse_FileRejected) isCodeResponse_Event() {}
+
+func (*CodeResponse_ToolCall) isCodeResponse_Event() {}
+
+func (*CodeResponse_ToolResult) isCodeResponse_Event() {}
+
+type TaskQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TaskId int64 `protobuf:"varint,1,opt,name=taskId,proto3" json:"taskId,omitempty"`
+}
+
+func (x *TaskQuery) Reset() {
+	*x = TaskQuery{}
+	mi := &file_protos_coder_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskQuery) ProtoMessage() {}
+
+func (x *TaskQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskQuery.ProtoReflect.Descriptor instead.
+func (*TaskQuery) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TaskQuery) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+type TaskInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TaskId      int64      `protobuf:"varint,1,opt,name=taskId,proto3" json:"taskId,omitempty"`
+	Status      TaskStatus `protobuf:"varint,2,opt,name=status,proto3,enum=coder.TaskStatus" json:"status,omitempty"`
+	UserPrompt  string     `protobuf:"bytes,3,opt,name=userPrompt,proto3" json:"userPrompt,omitempty"`
+	LLMModel    string     `protobuf:"bytes,4,opt,name=LLMModel,proto3" json:"LLMModel,omitempty"`
+	DockerImage string     `protobuf:"bytes,5,opt,name=dockerImage,proto3" json:"dockerImage,omitempty"`
+	CreatedAt   int64      `protobuf:"varint,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`
+	StartedAt   int64      `protobuf:"varint,7,opt,name=startedAt,proto3" json:"startedAt,omitempty"`
+	FinishedAt  int64      `protobuf:"varint,8,opt,name=finishedAt,proto3" json:"finishedAt,omitempty"`
+	Provider    string     `protobuf:"bytes,9,opt,name=provider,proto3" json:"provider,omitempty"`
+	Outcome     Outcome    `protobuf:"varint,10,opt,name=outcome,proto3,enum=coder.Outcome" json:"outcome,omitempty"`
+}
+
+func (x *TaskInfo) Reset() {
+	*x = TaskInfo{}
+	mi := &file_protos_coder_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskInfo) ProtoMessage() {}
+
+func (x *TaskInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskInfo.ProtoReflect.Descriptor instead.
+func (*TaskInfo) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TaskInfo) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetStatus() TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return TaskStatus_QUEUED
+}
+
+func (x *TaskInfo) GetUserPrompt() string {
+	if x != nil {
+		return x.UserPrompt
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetLLMModel() string {
+	if x != nil {
+		return x.LLMModel
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetDockerImage() string {
+	if x != nil {
+		return x.DockerImage
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetFinishedAt() int64 {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return 0
+}
+
+func (x *TaskInfo) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *TaskInfo) GetOutcome() Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return Outcome_OUTCOME_UNKNOWN
+}
+
+type ListTasksRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// only tasks in one of these states, all tasks when empty
+	Status []TaskStatus `protobuf:"varint,1,rep,packed,name=status,proto3,enum=coder.TaskStatus" json:"status,omitempty"`
+	Limit  int32        `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *ListTasksRequest) Reset() {
+	*x = ListTasksRequest{}
+	mi := &file_protos_coder_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksRequest) ProtoMessage() {}
+
+func (x *ListTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListTasksRequest) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListTasksRequest) GetStatus() []TaskStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *ListTasksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListTasksResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tasks []*TaskInfo `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+}
+
+func (x *ListTasksResponse) Reset() {
+	*x = ListTasksResponse{}
+	mi := &file_protos_coder_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTasksResponse) ProtoMessage() {}
+
+func (x *ListTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListTasksResponse) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTasksResponse) GetTasks() []*TaskInfo {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type LLMMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round   int32  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Content string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *LLMMessage) Reset() {
+	*x = LLMMessage{}
+	mi := &file_protos_coder_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LLMMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LLMMessage) ProtoMessage() {}
+
+func (x *LLMMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LLMMessage.ProtoReflect.Descriptor instead.
+func (*LLMMessage) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LLMMessage) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *LLMMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type FileExtracted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path     string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Language string `protobuf:"bytes,2,opt,name=language,proto3" json:"language,omitempty"`
+	Size     int64  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+}
+
+func (x *FileExtracted) Reset() {
+	*x = FileExtracted{}
+	mi := &file_protos_coder_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileExtracted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileExtracted) ProtoMessage() {}
+
+func (x *FileExtracted) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileExtracted.ProtoReflect.Descriptor instead.
+func (*FileExtracted) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FileExtracted) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileExtracted) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *FileExtracted) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// FileRejected is a code block of a reply that was not saved, its name
+// leaves the workspace, goes through a symlink or the task wrote too much,
+// or a hunk of a diff or search/replace block that did not match its file.
+// The model is told the same in its next prompt.
+type FileRejected struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Path   string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// the hunk that was not applied, as the model wrote it
+	Hunk string `protobuf:"bytes,3,opt,name=hunk,proto3" json:"hunk,omitempty"`
+}
+
+func (x *FileRejected) Reset() {
+	*x = FileRejected{}
+	mi := &file_protos_coder_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRejected) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRejected) ProtoMessage() {}
+
+func (x *FileRejected) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRejected.ProtoReflect.Descriptor instead.
+func (*FileRejected) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FileRejected) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *FileRejected) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FileRejected) GetHunk() string {
+	if x != nil {
+		return x.Hunk
+	}
+	return ""
+}
+
+type CommandStarted struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index   int32  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Command string `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
+}
+
+func (x *CommandStarted) Reset() {
+	*x = CommandStarted{}
+	mi := &file_protos_coder_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandStarted) ProtoMessage() {}
+
+func (x *CommandStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandStarted.ProtoReflect.Descriptor instead.
+func (*CommandStarted) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CommandStarted) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CommandStarted) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+type OutputChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index  int32        `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Stream OutputStream `protobuf:"varint,2,opt,name=stream,proto3,enum=coder.OutputStream" json:"stream,omitempty"`
+	Data   string       `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *OutputChunk) Reset() {
+	*x = OutputChunk{}
+	mi := &file_protos_coder_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutputChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputChunk) ProtoMessage() {}
+
+func (x *OutputChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputChunk.ProtoReflect.Descriptor instead.
+func (*OutputChunk) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *OutputChunk) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *OutputChunk) GetStream() OutputStream {
+	if x != nil {
+		return x.Stream
+	}
+	return OutputStream_STDOUT
+}
+
+func (x *OutputChunk) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+type CommandExited struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index    int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	ExitCode int32 `protobuf:"varint,2,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	// the command ran past its timeout and was killed
+	TimedOut bool `protobuf:"varint,3,opt,name=timedOut,proto3" json:"timedOut,omitempty"`
+}
+
+func (x *CommandExited) Reset() {
+	*x = CommandExited{}
+	mi := &file_protos_coder_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandExited) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandExited) ProtoMessage() {}
+
+func (x *CommandExited) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandExited.ProtoReflect.Descriptor instead.
+func (*CommandExited) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CommandExited) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *CommandExited) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *CommandExited) GetTimedOut() bool {
+	if x != nil {
+		return x.TimedOut
+	}
+	return false
+}
+
+type Retry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round    int32 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	MaxRetry int32 `protobuf:"varint,2,opt,name=maxRetry,proto3" json:"maxRetry,omitempty"`
+	ExitCode int32 `protobuf:"varint,3,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+}
+
+func (x *Retry) Reset() {
+	*x = Retry{}
+	mi := &file_protos_coder_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Retry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Retry) ProtoMessage() {}
+
+func (x *Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
+func (*Retry) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Retry) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Retry) GetMaxRetry() int32 {
+	if x != nil {
+		return x.MaxRetry
+	}
+	return 0
+}
+
+func (x *Retry) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+// Feedback is the message sent back to the model after running its code.
+type Feedback struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round   int32  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Content string `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+}
+
+func (x *Feedback) Reset() {
+	*x = Feedback{}
+	mi := &file_protos_coder_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feedback) ProtoMessage() {}
+
+func (x *Feedback) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feedback.ProtoReflect.Descriptor instead.
+func (*Feedback) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Feedback) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *Feedback) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+// WorkspaceSeeded reports what a seed placed in the workspace.
+type WorkspaceSeeded struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Files  int32  `protobuf:"varint,2,opt,name=files,proto3" json:"files,omitempty"`
+	Size   int64  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+}
+
+func (x *WorkspaceSeeded) Reset() {
+	*x = WorkspaceSeeded{}
+	mi := &file_protos_coder_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkspaceSeeded) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkspaceSeeded) ProtoMessage() {}
+
+func (x *WorkspaceSeeded) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkspaceSeeded.ProtoReflect.Descriptor instead.
+func (*WorkspaceSeeded) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WorkspaceSeeded) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *WorkspaceSeeded) GetFiles() int32 {
+	if x != nil {
+		return x.Files
+	}
+	return 0
+}
+
+func (x *WorkspaceSeeded) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// ImageProgress is a line of output while the image of a task is pulled or
+// built, before its first command runs.
+type ImageProgress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Image  string      `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Action ImageAction `protobuf:"varint,2,opt,name=action,proto3,enum=coder.ImageAction" json:"action,omitempty"`
+	Line   string      `protobuf:"bytes,3,opt,name=line,proto3" json:"line,omitempty"`
+}
+
+func (x *ImageProgress) Reset() {
+	*x = ImageProgress{}
+	mi := &file_protos_coder_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageProgress) ProtoMessage() {}
+
+func (x *ImageProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageProgress.ProtoReflect.Descriptor instead.
+func (*ImageProgress) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ImageProgress) GetImage() string {
+	if x != nil {
+		return x.Image
+	}
+	return ""
+}
+
+func (x *ImageProgress) GetAction() ImageAction {
+	if x != nil {
+		return x.Action
+	}
+	return ImageAction_IMAGE_PULL
+}
+
+func (x *ImageProgress) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+// ToolCall is a tool the model called in the tools agent mode.
+type ToolCall struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round int32  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Id    string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// JSON object
+	Arguments string `protobuf:"bytes,4,opt,name=arguments,proto3" json:"arguments,omitempty"`
+}
+
+func (x *ToolCall) Reset() {
+	*x = ToolCall{}
+	mi := &file_protos_coder_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolCall) ProtoMessage() {}
+
+func (x *ToolCall) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolCall.ProtoReflect.Descriptor instead.
+func (*ToolCall) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ToolCall) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *ToolCall) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ToolCall) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolCall) GetArguments() string {
+	if x != nil {
+		return x.Arguments
+	}
+	return ""
+}
+
+// ToolResult is what a tool call returned to the model.
+type ToolResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Round   int32  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Id      string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Content string `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	// the call failed, e.g. unknown tool, bad arguments or a path outside
+	// the workspace
+	Failed bool `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+}
+
+func (x *ToolResult) Reset() {
+	*x = ToolResult{}
+	mi := &file_protos_coder_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolResult) ProtoMessage() {}
+
+func (x *ToolResult) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolResult.ProtoReflect.Descriptor instead.
+func (*ToolResult) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ToolResult) GetRound() int32 {
+	if x != nil {
+		return x.Round
+	}
+	return 0
+}
+
+func (x *ToolResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ToolResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolResult) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ToolResult) GetFailed() bool {
+	if x != nil {
+		return x.Failed
+	}
+	return false
+}
+
+type Terminated struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	// what the model passed to finish in the tools agent mode
+	Result string `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *Terminated) Reset() {
+	*x = Terminated{}
+	mi := &file_protos_coder_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Terminated) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Terminated) ProtoMessage() {}
+
+func (x *Terminated) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Terminated.ProtoReflect.Descriptor instead.
+func (*Terminated) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Terminated) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Terminated) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+// Error reports a failure, the task goes on unless its summary has the
+// error outcome. A task failed by an error ends its stream with the
+// matching gRPC status, e.g. NOT_FOUND for `executor:image_not_found`.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// where it failed, e.g. `llm:generate`, `workspace:seed`, `executor:create`
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_protos_coder_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Summary is the last event of a coding task.
+type Summary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rounds int32 `protobuf:"varint,1,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	// total tokens, same as usage.totalTokens
+	LlmTokens int64 `protobuf:"varint,2,opt,name=llmTokens,proto3" json:"llmTokens,omitempty"`
+	// seconds
+	TimeTaken  int64         `protobuf:"varint,3,opt,name=timeTaken,proto3" json:"timeTaken,omitempty"`
+	Usage      *TokenUsage   `protobuf:"bytes,4,opt,name=usage,proto3" json:"usage,omitempty"`
+	RoundUsage []*TokenUsage `protobuf:"bytes,5,rep,name=roundUsage,proto3" json:"roundUsage,omitempty"`
+	Outcome    Outcome       `protobuf:"varint,6,opt,name=outcome,proto3,enum=coder.Outcome" json:"outcome,omitempty"`
+	// exit code of the last command run
+	ExitCode int32 `protobuf:"varint,7,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	// workspace files, relative to the workspace root
+	Files          []string `protobuf:"bytes,8,rep,name=files,proto3" json:"files,omitempty"`
+	WallTimeMillis int64    `protobuf:"varint,9,opt,name=wallTimeMillis,proto3" json:"wallTimeMillis,omitempty"`
+	// network policy the code actually ran with
+	Network NetworkPolicy `protobuf:"varint,10,opt,name=network,proto3,enum=coder.NetworkPolicy" json:"network,omitempty"`
+}
+
+func (x *Summary) Reset() {
+	*x = Summary{}
+	mi := &file_protos_coder_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Summary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Summary) ProtoMessage() {}
+
+func (x *Summary) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Summary.ProtoReflect.Descriptor instead.
+func (*Summary) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Summary) GetRounds() int32 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *Summary) GetLlmTokens() int64 {
+	if x != nil {
+		return x.LlmTokens
+	}
+	return 0
+}
+
+func (x *Summary) GetTimeTaken() int64 {
+	if x != nil {
+		return x.TimeTaken
+	}
+	return 0
+}
+
+func (x *Summary) GetUsage() *TokenUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *Summary) GetRoundUsage() []*TokenUsage {
+	if x != nil {
+		return x.RoundUsage
+	}
+	return nil
+}
+
+func (x *Summary) GetOutcome() Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return Outcome_OUTCOME_UNKNOWN
+}
+
+func (x *Summary) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *Summary) GetFiles() []string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *Summary) GetWallTimeMillis() int64 {
+	if x != nil {
+		return x.WallTimeMillis
+	}
+	return 0
+}
+
+func (x *Summary) GetNetwork() NetworkPolicy {
+	if x != nil {
+		return x.Network
+	}
+	return NetworkPolicy_NETWORK_UNSET
+}
+
+type TokenUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PromptTokens     int64 `protobuf:"varint,1,opt,name=promptTokens,proto3" json:"promptTokens,omitempty"`
+	CompletionTokens int64 `protobuf:"varint,2,opt,name=completionTokens,proto3" json:"completionTokens,omitempty"`
+	TotalTokens      int64 `protobuf:"varint,3,opt,name=totalTokens,proto3" json:"totalTokens,omitempty"`
+	// USD, from the [pricing] table of the server config
+	EstimatedCost float64 `protobuf:"fixed64,4,opt,name=estimatedCost,proto3" json:"estimatedCost,omitempty"`
+	// set when the provider reported no usage and tokens were counted locally
+	Counted bool `protobuf:"varint,5,opt,name=counted,proto3" json:"counted,omitempty"`
+}
+
+func (x *TokenUsage) Reset() {
+	*x = TokenUsage{}
+	mi := &file_protos_coder_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenUsage) ProtoMessage() {}
+
+func (x *TokenUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenUsage.ProtoReflect.Descriptor instead.
+func (*TokenUsage) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TokenUsage) GetPromptTokens() int64 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetCompletionTokens() int64 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetTotalTokens() int64 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetEstimatedCost() float64 {
+	if x != nil {
+		return x.EstimatedCost
+	}
+	return 0
+}
+
+func (x *TokenUsage) GetCounted() bool {
+	if x != nil {
+		return x.Counted
+	}
+	return false
+}
+
+type WorkspaceFile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// slash separated, relative to the workspace root
+	Path       string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Size       int64  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	ModifiedAt int64  `protobuf:"varint,3,opt,name=modifiedAt,proto3" json:"modifiedAt,omitempty"`
+}
+
+func (x *WorkspaceFile) Reset() {
+	*x = WorkspaceFile{}
+	mi := &file_protos_coder_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkspaceFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkspaceFile) ProtoMessage() {}
+
+func (x *WorkspaceFile) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkspaceFile.ProtoReflect.Descriptor instead.
+func (*WorkspaceFile) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WorkspaceFile) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *WorkspaceFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *WorkspaceFile) GetModifiedAt() int64 {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return 0
+}
+
+type WorkspaceListing struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TaskId int64            `protobuf:"varint,1,opt,name=taskId,proto3" json:"taskId,omitempty"`
+	Files  []*WorkspaceFile `protobuf:"bytes,2,rep,name=files,proto3" json:"files,omitempty"`
+}
+
+func (x *WorkspaceListing) Reset() {
+	*x = WorkspaceListing{}
+	mi := &file_protos_coder_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkspaceListing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkspaceListing) ProtoMessage() {}
+
+func (x *WorkspaceListing) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkspaceListing.ProtoReflect.Descriptor instead.
+func (*WorkspaceListing) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *WorkspaceListing) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *WorkspaceListing) GetFiles() []*WorkspaceFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type FileRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TaskId int64  `protobuf:"varint,1,opt,name=taskId,proto3" json:"taskId,omitempty"`
+	Path   string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *FileRequest) Reset() {
+	*x = FileRequest{}
+	mi := &file_protos_coder_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileRequest) ProtoMessage() {}
+
+func (x *FileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileRequest.ProtoReflect.Descriptor instead.
+func (*FileRequest) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *FileRequest) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *FileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type ArchiveRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TaskId int64         `protobuf:"varint,1,opt,name=taskId,proto3" json:"taskId,omitempty"`
+	Format ArchiveFormat `protobuf:"varint,2,opt,name=format,proto3,enum=coder.ArchiveFormat" json:"format,omitempty"`
+}
+
+func (x *ArchiveRequest) Reset() {
+	*x = ArchiveRequest{}
+	mi := &file_protos_coder_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveRequest) ProtoMessage() {}
+
+func (x *ArchiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveRequest.ProtoReflect.Descriptor instead.
+func (*ArchiveRequest) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ArchiveRequest) GetTaskId() int64 {
+	if x != nil {
+		return x.TaskId
+	}
+	return 0
+}
+
+func (x *ArchiveRequest) GetFormat() ArchiveFormat {
+	if x != nil {
+		return x.Format
+	}
+	return ArchiveFormat_TAR_GZ
+}
+
+// FileChunk is one piece of a download, name is only set on the first one.
+type FileChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	mi := &file_protos_coder_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunk) ProtoMessage() {}
+
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_coder_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_protos_coder_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *FileChunk) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+var File_protos_coder_proto protoreflect.FileDescriptor
+
+var file_protos_coder_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x22, 0x91, 0x03, 0x0a, 0x0b,
+	0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x73,
+	0x79, 0x73, 0x74, 0x65, 0x6d, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12,
+	0x1e, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12,
+	0x2a, 0x0a, 0x10, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x77, 0x6f, 0x72, 0x6b, 0x69,
+	0x6e, 0x67, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x64,
+	0x6f, 0x63, 0x6b, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x08, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x4c, 0x4d,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x4c, 0x4c, 0x4d,
+	0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65,
+	0x72, 0x12, 0x1f, 0x0a, 0x04, 0x73, 0x65, 0x65, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0b, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x53, 0x65, 0x65, 0x64, 0x52, 0x04, 0x73, 0x65,
+	0x65, 0x64, 0x12, 0x25, 0x0a, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x52, 0x06, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x64,
+	0x65, 0x72, 0x2e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x52, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x24, 0x0a, 0x04, 0x6d, 0x6f, 0x64,
+	0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e,
+	0x41, 0x67, 0x65, 0x6e, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x22,
+	0xea, 0x01, 0x0a, 0x06, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x70,
+	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x63, 0x70, 0x75, 0x73, 0x12, 0x1c,
+	0x0a, 0x09, 0x63, 0x70, 0x75, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x63, 0x70, 0x75, 0x53, 0x68, 0x61, 0x72, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x42, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x42, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x69, 0x64, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x70, 0x69, 0x64, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x74, 0x6d, 0x70, 0x66, 0x73, 0x4d, 0x42, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x74,
+	0x6d, 0x70, 0x66, 0x73, 0x4d, 0x42, 0x12, 0x34, 0x0a, 0x15, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x15, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x12,
+	0x74, 0x61, 0x73, 0x6b, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x12, 0x74, 0x61, 0x73, 0x6b, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0xa8, 0x01, 0x0a,
+	0x04, 0x53, 0x65, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00, 0x52, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76,
+	0x65, 0x12, 0x26, 0x0a, 0x0d, 0x67, 0x69, 0x74, 0x52, 0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f,
+	0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0d, 0x67, 0x69, 0x74, 0x52,
+	0x65, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x3a, 0x0a, 0x0d, 0x61, 0x72, 0x63,
+	0x68, 0x69, 0x76, 0x65, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x14, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65,
+	0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x0d, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x46,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x69, 0x74, 0x52, 0x65, 0x66, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x67, 0x69, 0x74, 0x52, 0x65, 0x66, 0x42, 0x08, 0x0a,
+	0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x89, 0x07, 0x0a, 0x0c, 0x43, 0x6f, 0x64, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06,
+	0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61,
+	0x73, 0x6b, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x12, 0x33, 0x0a, 0x0a, 0x6c, 0x6c, 0x6d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4c,
+	0x4c, 0x4d, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x48, 0x00, 0x52, 0x0a, 0x6c, 0x6c, 0x6d,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x3c, 0x0a, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x45,
+	0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x65, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x45, 0x78, 0x74, 0x72, 0x61,
+	0x63, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x45, 0x78, 0x74, 0x72,
+	0x61, 0x63, 0x74, 0x65, 0x64, 0x12, 0x3f, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61,
+	0x72, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0e, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53,
+	0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x48, 0x00, 0x52, 0x06, 0x6f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x12, 0x3c, 0x0a, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x45,
+	0x78, 0x69, 0x74, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f,
+	0x64, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x74, 0x65,
+	0x64, 0x48, 0x00, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x45, 0x78, 0x69, 0x74,
+	0x65, 0x64, 0x12, 0x24, 0x0a, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x48,
+	0x00, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x12, 0x33, 0x0a, 0x0a, 0x74, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x48,
+	0x00, 0x52, 0x0a, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x12, 0x24, 0x0a,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x2a, 0x0a, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x12,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x48, 0x00, 0x52, 0x07, 0x73, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12,
+	0x2b, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x48, 0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2d, 0x0a, 0x08,
+	0x66, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f,
+	0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x48,
+	0x00, 0x52, 0x08, 0x66, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x30, 0x0a, 0x06, 0x73,
+	0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x63, 0x6f,
+	0x64, 0x65, 0x72, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x53, 0x65, 0x65,
+	0x64, 0x65, 0x64, 0x48, 0x00, 0x52, 0x06, 0x73, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x2c, 0x0a,
+	0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x48, 0x00, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x39, 0x0a, 0x0c, 0x66,
+	0x69, 0x6c, 0x65, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x17, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x48, 0x00, 0x52, 0x0c, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x2d, 0x0a, 0x08, 0x74, 0x6f, 0x6f, 0x6c, 0x43, 0x61,
+	0x6c, 0x6c, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72,
+	0x2e, 0x54, 0x6f, 0x6f, 0x6c, 0x43, 0x61, 0x6c, 0x6c, 0x48, 0x00, 0x52, 0x08, 0x74, 0x6f, 0x6f,
+	0x6c, 0x43, 0x61, 0x6c, 0x6c, 0x12, 0x33, 0x0a, 0x0a, 0x74, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x64, 0x65,
+	0x72, 0x2e, 0x54, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x48, 0x00, 0x52, 0x0a,
+	0x74, 0x6f, 0x6f, 0x6c, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x22, 0x23, 0x0a, 0x09, 0x54, 0x61, 0x73, 0x6b, 0x51, 0x75, 0x65, 0x72, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x22, 0xcd, 0x02, 0x0a, 0x08, 0x54, 0x61, 0x73,
+	0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x12, 0x29, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e,
+	0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x75, 0x73, 0x65, 0x72,
+	0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x75, 0x73,
+	0x65, 0x72, 0x50, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x4c, 0x4c, 0x4d, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x4c, 0x4c, 0x4d, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x6f, 0x63, 0x6b, 0x65, 0x72, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x6f, 0x63, 0x6b, 0x65,
+	0x72, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64, 0x41, 0x74,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x66, 0x69, 0x6e, 0x69, 0x73, 0x68, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x28,
+	0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x0e, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x52,
+	0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x22, 0x53, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74,
+	0x54, 0x61, 0x73, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x3a, 0x0a,
+	0x11, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x73, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x25, 0x0a, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x49, 0x6e,
+	0x66, 0x6f, 0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x22, 0x3c, 0x0a, 0x0a, 0x4c, 0x4c, 0x4d,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x53, 0x0a, 0x0d, 0x46, 0x69, 0x6c, 0x65, 0x45,
+	0x78, 0x74, 0x72, 0x61, 0x63, 0x74, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x1a, 0x0a, 0x08,
+	0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x4e, 0x0a, 0x0c,
+	0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x75, 0x6e, 0x6b,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x75, 0x6e, 0x6b, 0x22, 0x40, 0x0a, 0x0e,
+	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x64,
+	0x0a, 0x0b, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x14, 0x0a,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x2b, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x22, 0x5d, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x45,
+	0x78, 0x69, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x65,
+	0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x65,
+	0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x22, 0x55, 0x0a, 0x05, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75,
+	0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x1a,
+	0x0a, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x22, 0x3a, 0x0a, 0x08, 0x46, 0x65,
+	0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x22, 0x53, 0x0a, 0x0f, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x53, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x65, 0x0a, 0x0d, 0x49,
+	0x6d, 0x61, 0x67, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x69, 0x6d, 0x61,
+	0x67, 0x65, 0x12, 0x2a, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x12, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65,
+	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12,
+	0x0a, 0x04, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6c, 0x69,
+	0x6e, 0x65, 0x22, 0x62, 0x0a, 0x08, 0x54, 0x6f, 0x6f, 0x6c, 0x43, 0x61, 0x6c, 0x6c, 0x12, 0x14,
+	0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x72,
+	0x6f, 0x75, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x72, 0x67, 0x75,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x72, 0x67,
+	0x75, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x78, 0x0a, 0x0a, 0x54, 0x6f, 0x6f, 0x6c, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x05, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c,
+	0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64,
+	0x22, 0x3c, 0x0a, 0x0a, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x12, 0x16,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x35,
+	0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xed, 0x02, 0x0a, 0x07, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72,
+	0x79, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6c, 0x6d,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x6c, 0x6c,
+	0x6d, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x54,
+	0x61, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65,
+	0x54, 0x61, 0x6b, 0x65, 0x6e, 0x12, 0x27, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x12, 0x31,
+	0x0a, 0x0a, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x55, 0x73, 0x61, 0x67, 0x65, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x11, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x0a, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x55, 0x73, 0x61, 0x67,
+	0x65, 0x12, 0x28, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4f, 0x75, 0x74, 0x63, 0x6f,
+	0x6d, 0x65, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x65,
+	0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x65,
+	0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x26, 0x0a,
+	0x0e, 0x77, 0x61, 0x6c, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x4d, 0x69, 0x6c, 0x6c, 0x69, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x77, 0x61, 0x6c, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x4d,
+	0x69, 0x6c, 0x6c, 0x69, 0x73, 0x12, 0x2e, 0x0a, 0x07, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x07, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x22, 0xbe, 0x01, 0x0a, 0x0a, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x55,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x6d, 0x70, 0x74, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x6d,
+	0x70, 0x74, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x2a, 0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x70,
+	0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x10, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x6f,
+	0x6b, 0x65, 0x6e, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x24, 0x0a, 0x0d, 0x65, 0x73, 0x74, 0x69, 0x6d, 0x61,
+	0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x65,
+	0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x64, 0x43, 0x6f, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x63,
+	0x6f, 0x75, 0x6e, 0x74, 0x65, 0x64, 0x22, 0x57, 0x0a, 0x0d, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x46, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x41, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0a, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x41, 0x74, 0x22,
+	0x56, 0x0a, 0x10, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74,
+	0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x12, 0x2a, 0x0a, 0x05, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x63, 0x6f, 0x64,
+	0x65, 0x72, 0x2e, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x46, 0x69, 0x6c, 0x65,
+	0x52, 0x05, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x39, 0x0a, 0x0b, 0x46, 0x69, 0x6c, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x22, 0x56, 0x0a, 0x0e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x61, 0x73, 0x6b, 0x49, 0x64, 0x12, 0x2c, 0x0a, 0x06,
+	0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x46, 0x6f, 0x72, 0x6d,
+	0x61, 0x74, 0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x22, 0x33, 0x0a, 0x09, 0x46, 0x69,
+	0x6c, 0x65, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x2a,
+	0x3d, 0x0a, 0x09, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x16,
+	0x41, 0x47, 0x45, 0x4e, 0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x43, 0x4f, 0x44, 0x45, 0x5f,
+	0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x53, 0x10, 0x00, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x47, 0x45, 0x4e,
+	0x54, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x54, 0x4f, 0x4f, 0x4c, 0x53, 0x10, 0x01, 0x2a, 0x5d,
+	0x0a, 0x0d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
+	0x11, 0x0a, 0x0d, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b, 0x5f, 0x55, 0x4e, 0x53, 0x45, 0x54,
+	0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b, 0x5f, 0x4e, 0x4f,
+	0x4e, 0x45, 0x10, 0x01, 0x12, 0x15, 0x0a, 0x11, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b, 0x5f,
+	0x41, 0x4c, 0x4c, 0x4f, 0x57, 0x4c, 0x49, 0x53, 0x54, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x4e,
+	0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b, 0x5f, 0x46, 0x55, 0x4c, 0x4c, 0x10, 0x03, 0x2a, 0x4f, 0x0a,
+	0x0a, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0a, 0x0a, 0x06, 0x51,
+	0x55, 0x45, 0x55, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49,
+	0x4e, 0x47, 0x10, 0x01, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4f, 0x4d, 0x50, 0x4c, 0x45, 0x54, 0x45,
+	0x44, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x4c, 0x45, 0x44,
+	0x10, 0x03, 0x12, 0x0a, 0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x2a, 0x26,
+	0x0a, 0x0c, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x0a,
+	0x0a, 0x06, 0x53, 0x54, 0x44, 0x4f, 0x55, 0x54, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54,
+	0x44, 0x45, 0x52, 0x52, 0x10, 0x01, 0x2a, 0x2e, 0x0a, 0x0b, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x0a, 0x49, 0x4d, 0x41, 0x47, 0x45, 0x5f, 0x50,
+	0x55, 0x4c, 0x4c, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4d, 0x41, 0x47, 0x45, 0x5f, 0x42,
+	0x55, 0x49, 0x4c, 0x44, 0x10, 0x01, 0x2a, 0x90, 0x01, 0x0a, 0x07, 0x4f, 0x75, 0x74, 0x63, 0x6f,
+	0x6d, 0x65, 0x12, 0x13, 0x0a, 0x0f, 0x4f, 0x55, 0x54, 0x43, 0x4f, 0x4d, 0x45, 0x5f, 0x55, 0x4e,
+	0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x16, 0x0a, 0x12, 0x4f, 0x55, 0x54, 0x43, 0x4f,
+	0x4d, 0x45, 0x5f, 0x54, 0x45, 0x52, 0x4d, 0x49, 0x4e, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12,
+	0x17, 0x0a, 0x13, 0x4f, 0x55, 0x54, 0x43, 0x4f, 0x4d, 0x45, 0x5f, 0x4d, 0x41, 0x58, 0x5f, 0x52,
+	0x45, 0x54, 0x52, 0x49, 0x45, 0x53, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x55, 0x54, 0x43,
+	0x4f, 0x4d, 0x45, 0x5f, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x03, 0x12,
+	0x11, 0x0a, 0x0d, 0x4f, 0x55, 0x54, 0x43, 0x4f, 0x4d, 0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52,
+	0x10, 0x04, 0x12, 0x15, 0x0a, 0x11, 0x4f, 0x55, 0x54, 0x43, 0x4f, 0x4d, 0x45, 0x5f, 0x54, 0x49,
+	0x4d, 0x45, 0x44, 0x5f, 0x4f, 0x55, 0x54, 0x10, 0x05, 0x2a, 0x24, 0x0a, 0x0d, 0x41, 0x72, 0x63,
+	0x68, 0x69, 0x76, 0x65, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x41,
+	0x52, 0x5f, 0x47, 0x5a, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x5a, 0x49, 0x50, 0x10, 0x01, 0x32,
+	0x85, 0x04, 0x0a, 0x0c, 0x43, 0x6f, 0x64, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x12, 0x38, 0x0a, 0x0b, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x64, 0x65, 0x12,
+	0x12, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x64, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x31, 0x0a, 0x0a, 0x53, 0x75,
+	0x62, 0x6d, 0x69, 0x74, 0x54, 0x61, 0x73, 0x6b, 0x12, 0x12, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72,
+	0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2c, 0x0a,
+	0x07, 0x47, 0x65, 0x74, 0x54, 0x61, 0x73, 0x6b, 0x12, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72,
+	0x2e, 0x54, 0x61, 0x73, 0x6b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x0f, 0x2e, 0x63, 0x6f, 0x64,
+	0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x35, 0x0a, 0x0a, 0x41,
+	0x74, 0x74, 0x61, 0x63, 0x68, 0x54, 0x61, 0x73, 0x6b, 0x12, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65,
+	0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x13, 0x2e, 0x63, 0x6f,
+	0x64, 0x65, 0x72, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x30, 0x01, 0x12, 0x2f, 0x0a, 0x0a, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x54, 0x61, 0x73, 0x6b,
+	0x12, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x51, 0x75, 0x65,
+	0x72, 0x79, 0x1a, 0x0f, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x3e, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x73, 0x6b, 0x73,
+	0x12, 0x17, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x73,
+	0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x63, 0x6f, 0x64, 0x65,
+	0x72, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x73, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x57, 0x6f, 0x72, 0x6b, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x12, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x73,
+	0x6b, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x17, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x57,
+	0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x69, 0x6e, 0x67, 0x12,
+	0x36, 0x0a, 0x0c, 0x44, 0x6f, 0x77, 0x6e, 0x6c, 0x6f, 0x61, 0x64, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x12, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x30, 0x01, 0x12, 0x3e, 0x0a, 0x11, 0x44, 0x6f, 0x77, 0x6e, 0x6c,
+	0x6f, 0x61, 0x64, 0x57, 0x6f, 0x72, 0x6b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x15, 0x2e, 0x63,
+	0x6f, 0x64, 0x65, 0x72, 0x2e, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x2e, 0x46, 0x69, 0x6c, 0x65,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x30, 0x01, 0x42, 0x0d, 0x5a, 0x0b, 0x2e, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x73, 0x2f, 0x67, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_protos_coder_proto_rawDescOnce sync.Once
+	file_protos_coder_proto_rawDescData = file_protos_coder_proto_rawDesc
+)
+
+func file_protos_coder_proto_rawDescGZIP() []byte {
+	file_protos_coder_proto_rawDescOnce.Do(func() {
+		file_protos_coder_proto_rawDescData = protoimpl.X.CompressGZIP(file_protos_coder_proto_rawDescData)
+	})
+	return file_protos_coder_proto_rawDescData
+}
+
+var file_protos_coder_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_protos_coder_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_protos_coder_proto_goTypes = []any{
+	(AgentMode)(0),            // 0: coder.AgentMode
+	(NetworkPolicy)(0),        // 1: coder.NetworkPolicy
+	(TaskStatus)(0),           // 2: coder.TaskStatus
+	(OutputStream)(0),         // 3: coder.OutputStream
+	(ImageAction)(0),          // 4: coder.ImageAction
+	(Outcome)(0),              // 5: coder.Outcome
+	(ArchiveFormat)(0),        // 6: coder.ArchiveFormat
+	(*CodeRequest)(nil),       // 7: coder.CodeRequest
+	(*Limits)(nil),            // 8: coder.Limits
+	(*Seed)(nil),              // 9: coder.Seed
+	(*CodeResponse)(nil),      // 10: coder.CodeResponse
+	(*TaskQuery)(nil),         // 11: coder.TaskQuery
+	(*TaskInfo)(nil),          // 12: coder.TaskInfo
+	(*ListTasksRequest)(nil),  // 13: coder.ListTasksRequest
+	(*ListTasksResponse)(nil), // 14: coder.ListTasksResponse
+	(*LLMMessage)(nil),        // 15: coder.LLMMessage
+	(*FileExtracted)(nil),     // 16: coder.FileExtracted
+	(*FileRejected)(nil),      // 17: coder.FileRejected
+	(*CommandStarted)(nil),    // 18: coder.CommandStarted
+	(*OutputChunk)(nil),       // 19: coder.OutputChunk
+	(*CommandExited)(nil),     // 20: coder.CommandExited
+	(*Retry)(nil),             // 21: coder.Retry
+	(*Feedback)(nil),          // 22: coder.Feedback
+	(*WorkspaceSeeded)(nil),   // 23: coder.WorkspaceSeeded
+	(*ImageProgress)(nil),     // 24: coder.ImageProgress
+	(*ToolCall)(nil),          // 25: coder.ToolCall
+	(*ToolResult)(nil),        // 26: coder.ToolResult
+	(*Terminated)(nil),        // 27: coder.Terminated
+	(*Error)(nil),             // 28: coder.Error
+	(*Summary)(nil),           // 29: coder.Summary
+	(*TokenUsage)(nil),        // 30: coder.TokenUsage
+	(*WorkspaceFile)(nil),     // 31: coder.WorkspaceFile
+	(*WorkspaceListing)(nil),  // 32: coder.WorkspaceListing
+	(*FileRequest)(nil),       // 33: coder.FileRequest
+	(*ArchiveRequest)(nil),    // 34: coder.ArchiveRequest
+	(*FileChunk)(nil),         // 35: coder.FileChunk
+}
+var file_protos_coder_proto_depIdxs = []int32{
+	9,  // 0: coder.CodeRequest.seed:type_name -> coder.Seed
+	8,  // 1: coder.CodeRequest.limits:type_name -> coder.Limits
+	1,  // 2: coder.CodeRequest.network:type_name -> coder.NetworkPolicy
+	0,  // 3: coder.CodeRequest.mode:type_name -> coder.AgentMode
+	6,  // 4: coder.Seed.archiveFormat:type_name -> coder.ArchiveFormat
+	15, // 5: coder.CodeResponse.llmMessage:type_name -> coder.LLMMessage
+	16, // 6: coder.CodeResponse.fileExtracted:type_name -> coder.FileExtracted
+	18, // 7: coder.CodeResponse.commandStarted:type_name -> coder.CommandStarted
+	19, // 8: coder.CodeResponse.output:type_name -> coder.OutputChunk
+	20, // 9: coder.CodeResponse.commandExited:type_name -> coder.CommandExited
+	21, // 10: coder.CodeResponse.retry:type_name -> coder.Retry
+	27, // 11: coder.CodeResponse.terminated:type_name -> coder.Terminated
+	28, // 12: coder.CodeResponse.error:type_name -> coder.Error
+	29, // 13: coder.CodeResponse.summary:type_name -> coder.Summary
+	2,  // 14: coder.CodeResponse.status:type_name -> coder.TaskStatus
+	22, // 15: coder.CodeResponse.feedback:type_name -> coder.Feedback
+	23, // 16: coder.CodeResponse.seeded:type_name -> coder.WorkspaceSeeded
+	24, // 17: coder.CodeResponse.image:type_name -> coder.ImageProgress
+	17, // 18: coder.CodeResponse.fileRejected:type_name -> coder.FileRejected
+	25, // 19: coder.CodeResponse.toolCall:type_name -> coder.ToolCall
+	26, // 20: coder.CodeResponse.toolResult:type_name -> coder.ToolResult
+	2,  // 21: coder.TaskInfo.status:type_name -> coder.TaskStatus
+	5,  // 22: coder.TaskInfo.outcome:type_name -> coder.Outcome
+	2,  // 23: coder.ListTasksRequest.status:type_name -> coder.TaskStatus
+	12, // 24: coder.ListTasksResponse.tasks:type_name -> coder.TaskInfo
+	3,  // 25: coder.OutputChunk.stream:type_name -> coder.OutputStream
+	4,  // 26: coder.ImageProgress.action:type_name -> coder.ImageAction
+	30, // 27: coder.Summary.usage:type_name -> coder.TokenUsage
+	30, // 28: coder.Summary.roundUsage:type_name -> coder.TokenUsage
+	5,  // 29: coder.Summary.outcome:type_name -> coder.Outcome
+	1,  // 30: coder.Summary.network:type_name -> coder.NetworkPolicy
+	31, // 31: coder.WorkspaceListing.files:type_name -> coder.WorkspaceFile
+	6,  // 32: coder.ArchiveRequest.format:type_name -> coder.ArchiveFormat
+	7,  // 33: coder.CoderService.ExecuteCode:input_type -> coder.CodeRequest
+	7,  // 34: coder.CoderService.SubmitTask:input_type -> coder.CodeRequest
+	11, // 35: coder.CoderService.GetTask:input_type -> coder.TaskQuery
+	11, // 36: coder.CoderService.AttachTask:input_type -> coder.TaskQuery
+	11, // 37: coder.CoderService.CancelTask:input_type -> coder.TaskQuery
+	13, // 38: coder.CoderService.ListTasks:input_type -> coder.ListTasksRequest
+	11, // 39: coder.CoderService.ListWorkspace:input_type -> coder.TaskQuery
+	33, // 40: coder.CoderService.DownloadFile:input_type -> coder.FileRequest
+	34, // 41: coder.CoderService.DownloadWorkspace:input_type -> coder.ArchiveRequest
+	10, // 42: coder.CoderService.ExecuteCode:output_type -> coder.CodeResponse
+	12, // 43: coder.CoderService.SubmitTask:output_type -> coder.TaskInfo
+	12, // 44: coder.CoderService.GetTask:output_type -> coder.TaskInfo
+	10, // 45: coder.CoderService.AttachTask:output_type -> coder.CodeResponse
+	12, // 46: coder.CoderService.CancelTask:output_type -> coder.TaskInfo
+	14, // 47: coder.CoderService.ListTasks:output_type -> coder.ListTasksResponse
+	32, // 48: coder.CoderService.ListWorkspace:output_type -> coder.WorkspaceListing
+	35, // 49: coder.CoderService.DownloadFile:output_type -> coder.FileChunk
+	35, // 50: coder.CoderService.DownloadWorkspace:output_type -> coder.FileChunk
+	42, // [42:51] is the sub-list for method output_type
+	33, // [33:42] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
+}
+
+func init() { file_protos_coder_proto_init() }
+func file_protos_coder_proto_init() {
+	if File_protos_coder_proto != nil {
+		return
+	}
+	file_protos_coder_proto_msgTypes[2].OneofWrappers = []any{
+		(*Seed_Archive)(nil),
+		(*Seed_GitRepository)(nil),
+	}
+	file_protos_coder_proto_msgTypes[3].OneofWrappers = []any{
+		(*CodeResponse_LlmMessage)(nil),
+		(*CodeResponse_FileExtracted)(nil),
+		(*CodeResponse_CommandStarted)(nil),
+		(*CodeResponse_Output)(nil),
+		(*CodeResponse_CommandExited)(nil),
+		(*CodeResponse_Retry)(nil),
+		(*CodeResponse_Terminated)(nil),
+		(*CodeResponse_Error)(nil),
+		(*CodeResponse_Summary)(nil),
+		(*CodeResponse_Status)(nil),
+		(*CodeResponse_Feedback)(nil),
+		(*CodeResponse_Seeded)(nil),
+		(*CodeResponse_Image)(nil),
+		(*CodeResponse_FileRejected)(nil),
+		(*CodeResponse_ToolCall)(nil),
+		(*CodeResponse_ToolResult)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_protos_coder_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      7,
+			NumMessages:   29,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_protos_coder_proto_goTypes,
 		DependencyIndexes: file_protos_coder_proto_depIdxs,
+		EnumInfos:         file_protos_coder_proto_enumTypes,
 		MessageInfos:      file_protos_coder_proto_msgTypes,
 	}.Build()
 	File_protos_coder_proto = out.File
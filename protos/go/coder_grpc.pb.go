@@ -19,14 +19,36 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CoderService_ExecuteCode_FullMethodName = "/coder.CoderService/ExecuteCode"
+	CoderService_ExecuteCode_FullMethodName       = "/coder.CoderService/ExecuteCode"
+	CoderService_SubmitTask_FullMethodName        = "/coder.CoderService/SubmitTask"
+	CoderService_GetTask_FullMethodName           = "/coder.CoderService/GetTask"
+	CoderService_AttachTask_FullMethodName        = "/coder.CoderService/AttachTask"
+	CoderService_CancelTask_FullMethodName        = "/coder.CoderService/CancelTask"
+	CoderService_ListTasks_FullMethodName         = "/coder.CoderService/ListTasks"
+	CoderService_ListWorkspace_FullMethodName     = "/coder.CoderService/ListWorkspace"
+	CoderService_DownloadFile_FullMethodName      = "/coder.CoderService/DownloadFile"
+	CoderService_DownloadWorkspace_FullMethodName = "/coder.CoderService/DownloadWorkspace"
 )
 
 // CoderServiceClient is the client API for CoderService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CoderServiceClient interface {
+	// ExecuteCode runs a task and streams its events, the task is cancelled
+	// when the client disconnects.
 	ExecuteCode(ctx context.Context, in *CodeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CodeResponse], error)
+	// SubmitTask queues a task that keeps running without an attached client.
+	SubmitTask(ctx context.Context, in *CodeRequest, opts ...grpc.CallOption) (*TaskInfo, error)
+	GetTask(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (*TaskInfo, error)
+	// AttachTask replays the events of a task so far and follows new ones.
+	AttachTask(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CodeResponse], error)
+	CancelTask(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (*TaskInfo, error)
+	ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error)
+	// ListWorkspace lists the files a task produced, including the
+	// `<container>_output.log` of its commands.
+	ListWorkspace(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (*WorkspaceListing, error)
+	DownloadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
+	DownloadWorkspace(ctx context.Context, in *ArchiveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
 }
 
 type coderServiceClient struct {
@@ -56,11 +78,132 @@ func (c *coderServiceClient) ExecuteCode(ctx context.Context, in *CodeRequest, o
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type CoderService_ExecuteCodeClient = grpc.ServerStreamingClient[CodeResponse]
 
+func (c *coderServiceClient) SubmitTask(ctx context.Context, in *CodeRequest, opts ...grpc.CallOption) (*TaskInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskInfo)
+	err := c.cc.Invoke(ctx, CoderService_SubmitTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coderServiceClient) GetTask(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (*TaskInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskInfo)
+	err := c.cc.Invoke(ctx, CoderService_GetTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coderServiceClient) AttachTask(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CodeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CoderService_ServiceDesc.Streams[1], CoderService_AttachTask_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[TaskQuery, CodeResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoderService_AttachTaskClient = grpc.ServerStreamingClient[CodeResponse]
+
+func (c *coderServiceClient) CancelTask(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (*TaskInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TaskInfo)
+	err := c.cc.Invoke(ctx, CoderService_CancelTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coderServiceClient) ListTasks(ctx context.Context, in *ListTasksRequest, opts ...grpc.CallOption) (*ListTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTasksResponse)
+	err := c.cc.Invoke(ctx, CoderService_ListTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coderServiceClient) ListWorkspace(ctx context.Context, in *TaskQuery, opts ...grpc.CallOption) (*WorkspaceListing, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WorkspaceListing)
+	err := c.cc.Invoke(ctx, CoderService_ListWorkspace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coderServiceClient) DownloadFile(ctx context.Context, in *FileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CoderService_ServiceDesc.Streams[2], CoderService_DownloadFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FileRequest, FileChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoderService_DownloadFileClient = grpc.ServerStreamingClient[FileChunk]
+
+func (c *coderServiceClient) DownloadWorkspace(ctx context.Context, in *ArchiveRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CoderService_ServiceDesc.Streams[3], CoderService_DownloadWorkspace_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ArchiveRequest, FileChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoderService_DownloadWorkspaceClient = grpc.ServerStreamingClient[FileChunk]
+
 // CoderServiceServer is the server API for CoderService service.
 // All implementations must embed UnimplementedCoderServiceServer
 // for forward compatibility.
 type CoderServiceServer interface {
+	// ExecuteCode runs a task and streams its events, the task is cancelled
+	// when the client disconnects.
 	ExecuteCode(*CodeRequest, grpc.ServerStreamingServer[CodeResponse]) error
+	// SubmitTask queues a task that keeps running without an attached client.
+	SubmitTask(context.Context, *CodeRequest) (*TaskInfo, error)
+	GetTask(context.Context, *TaskQuery) (*TaskInfo, error)
+	// AttachTask replays the events of a task so far and follows new ones.
+	AttachTask(*TaskQuery, grpc.ServerStreamingServer[CodeResponse]) error
+	CancelTask(context.Context, *TaskQuery) (*TaskInfo, error)
+	ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error)
+	// ListWorkspace lists the files a task produced, including the
+	// `<container>_output.log` of its commands.
+	ListWorkspace(context.Context, *TaskQuery) (*WorkspaceListing, error)
+	DownloadFile(*FileRequest, grpc.ServerStreamingServer[FileChunk]) error
+	DownloadWorkspace(*ArchiveRequest, grpc.ServerStreamingServer[FileChunk]) error
 	mustEmbedUnimplementedCoderServiceServer()
 }
 
@@ -74,6 +217,30 @@ type UnimplementedCoderServiceServer struct{}
 func (UnimplementedCoderServiceServer) ExecuteCode(*CodeRequest, grpc.ServerStreamingServer[CodeResponse]) error {
 	return status.Errorf(codes.Unimplemented, "method ExecuteCode not implemented")
 }
+func (UnimplementedCoderServiceServer) SubmitTask(context.Context, *CodeRequest) (*TaskInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTask not implemented")
+}
+func (UnimplementedCoderServiceServer) GetTask(context.Context, *TaskQuery) (*TaskInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTask not implemented")
+}
+func (UnimplementedCoderServiceServer) AttachTask(*TaskQuery, grpc.ServerStreamingServer[CodeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method AttachTask not implemented")
+}
+func (UnimplementedCoderServiceServer) CancelTask(context.Context, *TaskQuery) (*TaskInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelTask not implemented")
+}
+func (UnimplementedCoderServiceServer) ListTasks(context.Context, *ListTasksRequest) (*ListTasksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTasks not implemented")
+}
+func (UnimplementedCoderServiceServer) ListWorkspace(context.Context, *TaskQuery) (*WorkspaceListing, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWorkspace not implemented")
+}
+func (UnimplementedCoderServiceServer) DownloadFile(*FileRequest, grpc.ServerStreamingServer[FileChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadFile not implemented")
+}
+func (UnimplementedCoderServiceServer) DownloadWorkspace(*ArchiveRequest, grpc.ServerStreamingServer[FileChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadWorkspace not implemented")
+}
 func (UnimplementedCoderServiceServer) mustEmbedUnimplementedCoderServiceServer() {}
 func (UnimplementedCoderServiceServer) testEmbeddedByValue()                      {}
 
@@ -106,19 +273,178 @@ func _CoderService_ExecuteCode_Handler(srv interface{}, stream grpc.ServerStream
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type CoderService_ExecuteCodeServer = grpc.ServerStreamingServer[CodeResponse]
 
+func _CoderService_SubmitTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoderServiceServer).SubmitTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoderService_SubmitTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoderServiceServer).SubmitTask(ctx, req.(*CodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoderService_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoderServiceServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoderService_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoderServiceServer).GetTask(ctx, req.(*TaskQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoderService_AttachTask_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TaskQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoderServiceServer).AttachTask(m, &grpc.GenericServerStream[TaskQuery, CodeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoderService_AttachTaskServer = grpc.ServerStreamingServer[CodeResponse]
+
+func _CoderService_CancelTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoderServiceServer).CancelTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoderService_CancelTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoderServiceServer).CancelTask(ctx, req.(*TaskQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoderService_ListTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoderServiceServer).ListTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoderService_ListTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoderServiceServer).ListTasks(ctx, req.(*ListTasksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoderService_ListWorkspace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TaskQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoderServiceServer).ListWorkspace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CoderService_ListWorkspace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoderServiceServer).ListWorkspace(ctx, req.(*TaskQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoderService_DownloadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoderServiceServer).DownloadFile(m, &grpc.GenericServerStream[FileRequest, FileChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoderService_DownloadFileServer = grpc.ServerStreamingServer[FileChunk]
+
+func _CoderService_DownloadWorkspace_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ArchiveRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoderServiceServer).DownloadWorkspace(m, &grpc.GenericServerStream[ArchiveRequest, FileChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CoderService_DownloadWorkspaceServer = grpc.ServerStreamingServer[FileChunk]
+
 // CoderService_ServiceDesc is the grpc.ServiceDesc for CoderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var CoderService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "coder.CoderService",
 	HandlerType: (*CoderServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitTask",
+			Handler:    _CoderService_SubmitTask_Handler,
+		},
+		{
+			MethodName: "GetTask",
+			Handler:    _CoderService_GetTask_Handler,
+		},
+		{
+			MethodName: "CancelTask",
+			Handler:    _CoderService_CancelTask_Handler,
+		},
+		{
+			MethodName: "ListTasks",
+			Handler:    _CoderService_ListTasks_Handler,
+		},
+		{
+			MethodName: "ListWorkspace",
+			Handler:    _CoderService_ListWorkspace_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "ExecuteCode",
 			Handler:       _CoderService_ExecuteCode_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "AttachTask",
+			Handler:       _CoderService_AttachTask_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DownloadFile",
+			Handler:       _CoderService_DownloadFile_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DownloadWorkspace",
+			Handler:       _CoderService_DownloadWorkspace_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "protos/coder.proto",
 }
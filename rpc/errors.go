@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	pb "codexec/protos/go"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes maps the codes of error events that fail a task to the status
+// its stream ends with.
+var errorCodes = map[string]codes.Code{
+	"executor:image_not_found": codes.NotFound,
+	"executor:create":          codes.Unavailable,
+	"executor:exec":            codes.Internal,
+	"executor:timeout":         codes.DeadlineExceeded,
+	"executor:cancelled":       codes.Canceled,
+	"workspace:seed":           codes.InvalidArgument,
+	"llm:init":                 codes.FailedPrecondition,
+	"llm:generate":             codes.Unavailable,
+}
+
+// chatFailure keeps the error event failing a chat task, whose stream only
+// carries the text of the answer.
+type chatFailure struct {
+	mu    sync.Mutex
+	error *pb.Error
+}
+
+func (f *chatFailure) Emit(event *pb.CodeResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := event.GetError(); err != nil && f.error == nil {
+		f.error = err
+	}
+}
+
+func (f *chatFailure) event() *pb.Error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.error
+}
+
+// taskError returns the status of a task failed by event.
+func taskError(event *pb.Error) error {
+	code, ok := errorCodes[event.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	return status.Errorf(code, "%s: %s", event.Code, event.Message)
+}
@@ -0,0 +1,64 @@
+package rpc
+
+import (
+	"codexec/config"
+	"codexec/lib/buildpack"
+	dockerexecutor "codexec/lib/dockerExecutor"
+	"codexec/lib/executor"
+	localexecutor "codexec/lib/localExecutor"
+	pb "codexec/protos/go"
+	"codexec/types"
+	"log"
+)
+
+// executorBackend returns where commands run, `executor.backend` of
+// config.toml.
+func executorBackend() string {
+	return config.GetString("executor.backend", "docker")
+}
+
+// newExecutor returns the executor of the configured backend, the docker
+// one with its egress proxy, image builds and warm pool, and the egress
+// network of the allowlist policy when it is up.
+func newExecutor() (executor.Executor, dockerexecutor.Egress) {
+	switch backend := executorBackend(); backend {
+	case "local":
+		// local processes are not sandboxed, see localexecutor.Local
+		if !config.GetBoolPath([]string{"executor", "local", "unsandboxed"}, false) {
+			log.Fatalf("[EXECUTOR] the local backend runs code unsandboxed with access to the files of the server, set executor.local.unsandboxed = true to use it anyway")
+		}
+		namespaces := config.GetBoolPath([]string{"executor", "local", "namespaces"}, true)
+		log.Printf("[EXECUTOR] running commands as unsandboxed local processes, namespaces: %t", namespaces)
+		return executor.New(localexecutor.NewLocal(namespaces)), dockerexecutor.Egress{}
+	default:
+		if backend != "docker" {
+			log.Printf("[EXECUTOR] unknown backend %q, using docker", backend)
+		}
+	}
+
+	var egress dockerexecutor.Egress
+	if configuredPolicy("network.maxPolicy", pb.NetworkPolicy_NETWORK_ALLOWLIST) >= pb.NetworkPolicy_NETWORK_ALLOWLIST {
+		var err error
+		egress, err = startEgress()
+		if err != nil {
+			log.Printf("[NETWORK] allowlist policy unavailable, its tasks run without network: %v", err)
+		}
+	}
+	images := dockerexecutor.NewImages(
+		config.GetString("images.buildpacks", "code.buildpacks"),
+		config.GetString("images.mirror", ""),
+		config.GetBoolPath([]string{"images", "pull"}, true),
+	)
+	return executor.New(dockerexecutor.NewDocker(egress, images, startPool(egress, images))), egress
+}
+
+// buildpackFor returns the buildpack running image, nil when there is none
+// and only shell blocks run.
+func buildpackFor(image string) *types.Buildpack {
+	registry, err := buildpack.Load(config.GetString("images.buildpacks", "code.buildpacks"))
+	if err != nil {
+		log.Printf("[EXECUTOR] failed to load buildpacks: %v", err)
+		return nil
+	}
+	return registry.ForImage(image)
+}
@@ -0,0 +1,419 @@
+package rpc
+
+import (
+	"codexec/config"
+	"codexec/lib/events"
+	"codexec/lib/store"
+	"codexec/logger"
+	pb "codexec/protos/go"
+	"codexec/types"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events an attached client may lag behind
+// before it is dropped, it can re-attach and get the full replay.
+const subscriberBuffer = 256
+
+// maxReplayChunk is the size consecutive output is merged up to in the
+// history, replaying it takes a few events rather than one per flush.
+const maxReplayChunk = 32 * 1024
+
+// Job is a submitted task together with everything it emitted so far.
+type Job struct {
+	mu         sync.Mutex
+	Task       types.Task
+	Status     pb.TaskStatus
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	failed     bool
+	lastError  *pb.Error
+	outcome    pb.Outcome
+	store      store.Store
+	// persisting is held while an event is written to the store, it is
+	// taken before mu is released so records see events in order
+	persisting sync.Mutex
+	history    []*pb.CodeResponse
+	// output is the size of the output in history, dropped what was cut
+	// from its start to stay within `jobs.maxReplayOutputKB`
+	output      int
+	dropped     int
+	maxOutput   int
+	subscribers map[chan *pb.CodeResponse]struct{}
+	done        chan struct{}
+}
+
+type JobRegistry struct {
+	mu    sync.Mutex
+	jobs  map[int]*Job
+	store store.Store
+}
+
+// NewJobRegistry returns a registry recording tasks in taskStore, which may
+// be nil to keep them in memory only.
+func NewJobRegistry(taskStore store.Store) *JobRegistry {
+	return &JobRegistry{jobs: make(map[int]*Job), store: taskStore}
+}
+
+// Create registers a new job for task and assigns it an unused id, the
+// task's events are recorded from then on.
+func (r *JobRegistry) Create(task types.Task) *Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune()
+
+	task.Id = GenerateRandomID()
+	for r.taken(task.Id) {
+		task.Id = GenerateRandomID()
+	}
+	task.ContainerName = containerNameFor(task.Id)
+
+	job := &Job{
+		Status:      pb.TaskStatus_QUEUED,
+		CreatedAt:   time.Now(),
+		store:       r.store,
+		maxOutput:   config.GetInt("jobs.maxReplayOutputKB", 1024) << 10,
+		subscribers: make(map[chan *pb.CodeResponse]struct{}),
+		done:        make(chan struct{}),
+	}
+	task.Events = job
+	task.Logger = log.New(logger.NewEventWriter(job), "", 0)
+	job.Task = task
+	r.jobs[task.Id] = job
+
+	if r.store != nil {
+		err := r.store.Create(&store.TaskRecord{
+			Id:     task.Id,
+			Status: job.Status.String(),
+			Request: store.Request{
+				SystemPrompt: task.SystemPrompt,
+				UserPrompt:   task.UserPrompt,
+				DockerImage:  task.DockerImage,
+				MaxRetry:     task.MaxRetry,
+				LLMProvider:  task.LLMProvider,
+				LLMModel:     task.LLMModel,
+				Seed:         seedSource(task.Seed),
+				Limits:       task.Limits,
+				Network:      task.Network.String(),
+			},
+			ContainerName:    task.ContainerName,
+			WorkingDirectory: workspaceFor(task.ContainerName),
+			CreatedAt:        job.CreatedAt,
+		})
+		if err != nil {
+			log.Printf("[STORE] (%d) failed to record task: %v", task.Id, err)
+		}
+	}
+	return job
+}
+
+// Remove forgets a job that never got queued, along with its record.
+func (r *JobRegistry) Remove(id int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, id)
+	if r.store != nil {
+		if err := r.store.Delete(id); err != nil {
+			log.Printf("[STORE] (%d) failed to delete task: %v", id, err)
+		}
+	}
+}
+
+// taken reports whether id belongs to a live or a stored task, callers hold r.mu.
+func (r *JobRegistry) taken(id int) bool {
+	if r.jobs[id] != nil {
+		return true
+	}
+	if r.store == nil {
+		return false
+	}
+	_, err := r.store.Get(id)
+	return err != store.ErrNotFound
+}
+
+func (r *JobRegistry) Get(id int) (*Job, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	return job, ok
+}
+
+// Record returns the stored record of a task that is no longer live.
+func (r *JobRegistry) Record(id int) (*store.TaskRecord, error) {
+	if r.store == nil {
+		return nil, store.ErrNotFound
+	}
+	return r.store.Get(id)
+}
+
+// Workspace returns the host directory holding the files of a live or stored task.
+func (r *JobRegistry) Workspace(id int) (string, error) {
+	if job, ok := r.Get(id); ok {
+		return workspaceFor(job.Task.ContainerName), nil
+	}
+	record, err := r.Record(id)
+	if err != nil {
+		return "", err
+	}
+	return record.WorkingDirectory, nil
+}
+
+// List returns live and stored tasks in one of the given states (all when
+// empty), newest first.
+func (r *JobRegistry) List(statuses []pb.TaskStatus, limit int) ([]*pb.TaskInfo, error) {
+	r.mu.Lock()
+	var infos []*pb.TaskInfo
+	for _, job := range r.jobs {
+		infos = append(infos, job.Info())
+	}
+	r.mu.Unlock()
+
+	if r.store != nil {
+		records, err := r.store.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if _, live := r.Get(record.Id); !live {
+				infos = append(infos, record.Info())
+			}
+		}
+	}
+
+	var matching []*pb.TaskInfo
+	for _, info := range infos {
+		if len(statuses) == 0 || containsStatus(statuses, info.Status) {
+			matching = append(matching, info)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return matching[i].CreatedAt > matching[j].CreatedAt
+	})
+	if limit > 0 && len(matching) > limit {
+		matching = matching[:limit]
+	}
+	return matching, nil
+}
+
+// prune forgets finished jobs past the retention window, callers hold r.mu.
+// Their records stay in the store.
+func (r *JobRegistry) prune() {
+	retention := time.Duration(config.GetInt("jobs.retentionMinutes", 60)) * time.Minute
+	for id, job := range r.jobs {
+		info := job.Info()
+		if info.FinishedAt != 0 && time.Since(time.UnixMilli(info.FinishedAt)) > retention {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+func containsStatus(statuses []pb.TaskStatus, status pb.TaskStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Emit records an event in the job history and fans it out to attached
+// clients. The history keeps the last `jobs.maxReplayOutputKB` of output.
+func (j *Job) Emit(event *pb.CodeResponse) {
+	j.mu.Lock()
+
+	event.TaskId = int64(j.Task.Id)
+	event.Timestamp = time.Now().UnixMilli()
+
+	switch e := event.Event.(type) {
+	case *pb.CodeResponse_Status:
+		j.Status = e.Status
+		if e.Status == pb.TaskStatus_RUNNING {
+			j.StartedAt = time.Now()
+		}
+	case *pb.CodeResponse_Error:
+		j.failed = true
+		j.lastError = e.Error
+	case *pb.CodeResponse_Summary:
+		j.outcome = e.Summary.Outcome
+	}
+
+	j.record(event)
+	for subscriber := range j.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			log.Printf("[RPC] (%d) dropping slow subscriber", j.Task.Id)
+			delete(j.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+
+	if j.store == nil || !store.Persisted(event) {
+		j.mu.Unlock()
+		return
+	}
+	j.persisting.Lock()
+	j.mu.Unlock()
+	defer j.persisting.Unlock()
+	if err := j.store.Append(j.Task.Id, event); err != nil {
+		log.Printf("[STORE] (%d) failed to record event: %v", j.Task.Id, err)
+	}
+}
+
+// record adds event to the history, output is merged into the output right
+// before it and the oldest output cut once there is too much, callers hold
+// j.mu. Events already in the history are replaced rather than changed,
+// subscribers may still hold them.
+func (j *Job) record(event *pb.CodeResponse) {
+	output := event.GetOutput()
+	if output == nil {
+		j.history = append(j.history, event)
+		return
+	}
+
+	j.output += len(output.Data)
+	last := len(j.history) - 1
+	if previous := j.lastOutput(); previous != nil && previous.Index == output.Index && previous.Stream == output.Stream &&
+		len(previous.Data)+len(output.Data) <= maxReplayChunk {
+		merged := events.Output(output.Index, output.Stream, previous.Data+output.Data)
+		merged.TaskId, merged.Timestamp = event.TaskId, event.Timestamp
+		j.history[last] = merged
+	} else {
+		j.history = append(j.history, event)
+	}
+
+	for i := 0; j.maxOutput > 0 && j.output > j.maxOutput && i < len(j.history); {
+		chunk := j.history[i].GetOutput()
+		if chunk == nil {
+			i++
+			continue
+		}
+		cut := min(len(chunk.Data), j.output-j.maxOutput)
+		j.output -= cut
+		j.dropped += cut
+		if cut == len(chunk.Data) {
+			j.history = append(j.history[:i], j.history[i+1:]...)
+			continue
+		}
+		trimmed := events.Output(chunk.Index, chunk.Stream, chunk.Data[cut:])
+		trimmed.TaskId, trimmed.Timestamp = j.history[i].TaskId, j.history[i].Timestamp
+		j.history[i] = trimmed
+	}
+}
+
+// lastOutput returns the output chunk ending the history, nil when the
+// history ends with another event.
+func (j *Job) lastOutput() *pb.OutputChunk {
+	if len(j.history) == 0 {
+		return nil
+	}
+	return j.history[len(j.history)-1].GetOutput()
+}
+
+// Subscribe returns the events emitted so far and a channel carrying the
+// following ones, the channel is closed when the job finishes.
+func (j *Job) Subscribe() ([]*pb.CodeResponse, chan *pb.CodeResponse) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	history := make([]*pb.CodeResponse, 0, len(j.history)+1)
+	noted := j.dropped == 0
+	for _, event := range j.history {
+		// the note goes where the output it stands for was
+		if chunk := event.GetOutput(); chunk != nil && !noted {
+			note := events.Output(chunk.Index, chunk.Stream, fmt.Sprintf("[... %d bytes of earlier output dropped ...]\n", j.dropped))
+			note.TaskId, note.Timestamp = event.TaskId, event.Timestamp
+			history = append(history, note)
+			noted = true
+		}
+		history = append(history, event)
+	}
+
+	subscriber := make(chan *pb.CodeResponse, subscriberBuffer)
+	if j.FinishedAt.IsZero() {
+		j.subscribers[subscriber] = struct{}{}
+	} else {
+		close(subscriber)
+	}
+	return history, subscriber
+}
+
+func (j *Job) Unsubscribe(subscriber chan *pb.CodeResponse) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.subscribers[subscriber]; ok {
+		delete(j.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// Wait marks the job finished once its worker signals completion.
+func (j *Job) Wait(completeSignal <-chan bool) {
+	<-completeSignal
+
+	status := pb.TaskStatus_COMPLETED
+	j.mu.Lock()
+	if j.Task.Context.Err() != nil {
+		status = pb.TaskStatus_CANCELLED
+	} else if j.outcome == pb.Outcome_OUTCOME_ERROR || j.outcome == pb.Outcome_OUTCOME_TIMED_OUT || (j.outcome == pb.Outcome_OUTCOME_UNKNOWN && j.failed) {
+		status = pb.TaskStatus_FAILED
+	}
+	j.mu.Unlock()
+	j.Emit(events.Status(status))
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.FinishedAt = time.Now()
+	// done is closed first so a subscriber seeing its channel closed can
+	// tell a finished job from being dropped for lagging behind
+	close(j.done)
+	for subscriber := range j.subscribers {
+		close(subscriber)
+	}
+	j.subscribers = make(map[chan *pb.CodeResponse]struct{})
+}
+
+// Err returns the status of a job failed by an error event, nil for any
+// other end.
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.Status != pb.TaskStatus_FAILED || j.outcome == pb.Outcome_OUTCOME_TIMED_OUT || j.lastError == nil {
+		return nil
+	}
+	return taskError(j.lastError)
+}
+
+// Done is closed once the job has finished.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+func (j *Job) Info() *pb.TaskInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return &pb.TaskInfo{
+		TaskId:      int64(j.Task.Id),
+		Status:      j.Status,
+		UserPrompt:  j.Task.UserPrompt,
+		LLMModel:    j.Task.LLMModel,
+		Provider:    j.Task.LLMProvider,
+		Outcome:     j.outcome,
+		DockerImage: j.Task.DockerImage,
+		CreatedAt:   unixMilli(j.CreatedAt),
+		StartedAt:   unixMilli(j.StartedAt),
+		FinishedAt:  unixMilli(j.FinishedAt),
+	}
+}
+
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"codexec/config"
+	pb "codexec/protos/go"
+	"codexec/types"
+	"time"
+)
+
+// limitsFor merges the limits a request asks for with the [limits] section
+// of config.toml, which holds both the defaults and the ceilings.
+func limitsFor(req *pb.Limits) types.Limits {
+	seconds := func(requested int32, key string, fallback int) time.Duration {
+		return time.Duration(lower(int64(requested), int64(config.GetInt(key, fallback)))) * time.Second
+	}
+	return types.Limits{
+		CPUs:           lower(req.GetCpus(), config.GetFloatPath([]string{"limits", "cpus"}, 1)),
+		CPUShares:      lower(req.GetCpuShares(), int64(config.GetInt("limits.cpuShares", 1024))),
+		MemoryMB:       lower(req.GetMemoryMB(), int64(config.GetInt("limits.memoryMB", 512))),
+		Pids:           lower(req.GetPids(), int64(config.GetInt("limits.pids", 256))),
+		TmpfsMB:        lower(req.GetTmpfsMB(), int64(config.GetInt("limits.tmpfsMB", 64))),
+		CommandTimeout: seconds(req.GetCommandTimeoutSeconds(), "limits.commandTimeoutSeconds", 120),
+		TaskTimeout:    seconds(req.GetTaskTimeoutSeconds(), "limits.taskTimeoutSeconds", 900),
+	}
+}
+
+// lower returns requested unless it is unset or above configured, a
+// configured zero means unlimited.
+func lower[T int64 | float64](requested T, configured T) T {
+	if requested <= 0 {
+		return configured
+	}
+	if configured > 0 && requested > configured {
+		return configured
+	}
+	return requested
+}
@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"codexec/config"
+	dockerexecutor "codexec/lib/dockerExecutor"
+	"codexec/lib/egress"
+	pb "codexec/protos/go"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// networkPolicyFor returns the policy a task runs with, the configured
+// default unless the request asks for one within `network.maxPolicy`.
+func networkPolicyFor(requested pb.NetworkPolicy) (pb.NetworkPolicy, error) {
+	if requested == pb.NetworkPolicy_NETWORK_UNSET {
+		return configuredPolicy("network.policy", pb.NetworkPolicy_NETWORK_NONE), nil
+	}
+	if _, ok := pb.NetworkPolicy_name[int32(requested)]; !ok {
+		return requested, status.Errorf(codes.InvalidArgument, "unknown network policy %v", requested)
+	}
+	if ceiling := configuredPolicy("network.maxPolicy", pb.NetworkPolicy_NETWORK_ALLOWLIST); requested > ceiling {
+		return requested, status.Errorf(codes.PermissionDenied, "network policy %v is above the allowed %v", requested, ceiling)
+	}
+	return requested, nil
+}
+
+// configuredPolicy reads a policy name like "allowlist" from config.toml.
+func configuredPolicy(key string, fallback pb.NetworkPolicy) pb.NetworkPolicy {
+	name := config.GetString(key, "")
+	if name == "" {
+		return fallback
+	}
+	policy, ok := pb.NetworkPolicy_value["NETWORK_"+strings.ToUpper(name)]
+	if !ok || policy == int32(pb.NetworkPolicy_NETWORK_UNSET) {
+		log.Printf("[NETWORK] unknown policy %q for %s, using %v", name, key, fallback)
+		return fallback
+	}
+	return pb.NetworkPolicy(policy)
+}
+
+// checkListen refuses to serve the API on the gateway of the network of the
+// allowlist policy, containers on it reach the host through that address.
+func checkListen(addr string, egress dockerexecutor.Egress) error {
+	if egress.Gateway == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.Equal(net.ParseIP(egress.Gateway)) {
+		return fmt.Errorf("%s is the gateway of network %s, set app.listen to another address", addr, egress.Network)
+	}
+	return nil
+}
+
+// offGateway drops the connections made to the API through the gateway of
+// the network of the allowlist policy, as they come from sandboxed code
+// when the API listens on all interfaces.
+type offGateway struct {
+	net.Listener
+	gateway net.IP
+}
+
+func guardListener(lis net.Listener, egress dockerexecutor.Egress) net.Listener {
+	if egress.Gateway == "" {
+		return lis
+	}
+	return offGateway{Listener: lis, gateway: net.ParseIP(egress.Gateway)}
+}
+
+func (l offGateway) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if local, ok := conn.LocalAddr().(*net.TCPAddr); ok && local.IP.Equal(l.gateway) {
+			log.Printf("[NETWORK] dropped API connection from %s through the egress gateway", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+// startEgress sets up the internal docker network of the allowlist policy
+// and serves the egress proxy on its gateway.
+func startEgress() (dockerexecutor.Egress, error) {
+	name := config.GetString("network.allowlistNetwork", "codexec-egress")
+	gateway, err := dockerexecutor.EnsureNetwork(name)
+	if err != nil {
+		return dockerexecutor.Egress{}, err
+	}
+
+	addr := net.JoinHostPort(gateway, strconv.Itoa(config.GetInt("network.proxyPort", 3128)))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return dockerexecutor.Egress{}, err
+	}
+	proxy := egress.NewProxy(config.GetStrings("network.allowlist"))
+	go func() {
+		if err := http.Serve(lis, proxy); err != nil {
+			log.Printf("[NETWORK] egress proxy stopped: %v", err)
+		}
+	}()
+
+	log.Printf("[NETWORK] egress proxy listening on %s for network %s", addr, name)
+	return dockerexecutor.Egress{Network: name, ProxyURL: "http://" + addr, Gateway: gateway}, nil
+}
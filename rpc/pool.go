@@ -0,0 +1,35 @@
+package rpc
+
+import (
+	"codexec/config"
+	dockerexecutor "codexec/lib/dockerExecutor"
+	pb "codexec/protos/go"
+	"log"
+	"path/filepath"
+	"time"
+)
+
+// startPool starts the warm containers configured in [pool], nil when no
+// image has any.
+func startPool(egress dockerexecutor.Egress, images *dockerexecutor.Images) *dockerexecutor.Pool {
+	sizes := config.GetIntMap("pool.images")
+	if len(sizes) == 0 {
+		return nil
+	}
+
+	pool := &dockerexecutor.Pool{
+		Sizes:   sizes,
+		MaxUses: config.GetInt("pool.maxUses", 10),
+		Dir:     filepath.Join(config.GetString("app.codingDirectory", "coding/"), "codexec-pool"),
+		Limits:  limitsFor(nil),
+		Network: configuredPolicy("network.policy", pb.NetworkPolicy_NETWORK_NONE),
+		Egress:  egress,
+		Images:  images,
+	}
+	interval := time.Duration(config.GetInt("pool.healthCheckSeconds", 30)) * time.Second
+	if err := pool.Start(interval); err != nil {
+		log.Printf("[POOL] warm containers unavailable: %v", err)
+		return nil
+	}
+	return pool
+}
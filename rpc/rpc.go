@@ -1,6 +1,11 @@
 package rpc
 
 import (
+	"codexec/config"
+	dockerexecutor "codexec/lib/dockerExecutor"
+	"codexec/lib/events"
+	"codexec/lib/llm"
+	"codexec/lib/store"
 	"codexec/logger"
 	pb "codexec/protos/go"
 	"codexec/types"
@@ -9,41 +14,232 @@ import (
 	"net"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CoderServiceServer struct {
 	pb.UnimplementedCoderServiceServer
 	workerPool *WorkerPoolAdapter
+	jobs       *JobRegistry
 }
 
+type StreamServiceServer struct {
+	pb.UnimplementedStreamServiceServer
+	workerPool *WorkerPoolAdapter
+}
+
+// ExecuteCode runs the task bound to the lifetime of the call, a client
+// disconnect cancels it.
 func (s *CoderServiceServer) ExecuteCode(req *pb.CodeRequest, stream pb.CoderService_ExecuteCodeServer) error {
+	job, err := s.submit(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	return s.attach(job, stream)
+}
+
+func (s *CoderServiceServer) SubmitTask(ctx context.Context, req *pb.CodeRequest) (*pb.TaskInfo, error) {
+	job, err := s.submit(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	return job.Info(), nil
+}
+
+func (s *CoderServiceServer) GetTask(ctx context.Context, req *pb.TaskQuery) (*pb.TaskInfo, error) {
+	job, err := s.lookup(req.TaskId)
+	if err != nil {
+		return s.storedInfo(req.TaskId, err)
+	}
+	return job.Info(), nil
+}
+
+// AttachTask follows a live task, a task finished by an earlier server
+// process only reports its final status.
+func (s *CoderServiceServer) AttachTask(req *pb.TaskQuery, stream pb.CoderService_AttachTaskServer) error {
+	job, err := s.lookup(req.TaskId)
+	if err != nil {
+		info, err := s.storedInfo(req.TaskId, err)
+		if err != nil {
+			return err
+		}
+		response := events.Status(info.Status)
+		response.TaskId = info.TaskId
+		response.Timestamp = info.FinishedAt
+		return stream.Send(response)
+	}
+	return s.attach(job, stream)
+}
+
+func (s *CoderServiceServer) CancelTask(ctx context.Context, req *pb.TaskQuery) (*pb.TaskInfo, error) {
+	job, err := s.lookup(req.TaskId)
+	if err != nil {
+		return s.storedInfo(req.TaskId, err)
+	}
+	log.Printf("[RPC] cancel requested for task (%d)", job.Task.Id)
+	job.Task.Cancel()
+	return job.Info(), nil
+}
+
+func (s *CoderServiceServer) ListTasks(ctx context.Context, req *pb.ListTasksRequest) (*pb.ListTasksResponse, error) {
+	infos, err := s.jobs.List(req.Status, int(req.Limit))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list tasks: %v", err)
+	}
+	return &pb.ListTasksResponse{Tasks: infos}, nil
+}
+
+// submit registers a job for req and queues it, the task lives as long as ctx.
+func (s *CoderServiceServer) submit(ctx context.Context, req *pb.CodeRequest) (*Job, error) {
+	if err := checkSeed(req.Seed); err != nil {
+		return nil, err
+	}
+	network, err := networkPolicyFor(req.Network)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := providerFor(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// buffered so the worker never blocks signalling a task nobody waits for anymore
 	CompleteSignal := make(chan bool, 1)
-	defer close(CompleteSignal)
 
-	ctx, cancel := context.WithCancel(stream.Context())
-	defer cancel()
+	ctx, cancel := context.WithCancel(ctx)
 
-	streamWriter := logger.NewCodeStreamWriter(stream)
-	streamLogger := log.New(streamWriter, "", 0)
+	kind := types.TaskKindCode
+	if req.Mode == pb.AgentMode_AGENT_MODE_TOOLS {
+		kind = types.TaskKindTools
+	}
 
-	task := types.Task{
-		Id:               GenerateRandomID(),
+	job := s.jobs.Create(types.Task{
+		Kind:             kind,
 		CompleteSignal:   CompleteSignal,
 		SystemPrompt:     req.SystemPrompt,
 		UserPrompt:       req.UserPrompt,
 		WorkingDirectory: req.WorkingDirectory,
 		DockerImage:      req.DockerImage,
 		MaxRetry:         req.MaxRetry,
-		LLMModel:         req.LLMModel,
-		Logger:           streamLogger,
+		LLMProvider:      provider,
+		LLMModel:         modelOrDefault(req.LLMModel),
+		Seed:             req.Seed,
+		Limits:           limitsFor(req.Limits),
+		Network:          network,
+		Buildpack:        buildpackFor(req.DockerImage),
 		Context:          ctx,
 		Cancel:           cancel,
+	})
+
+	if !s.workerPool.TrySubmitTask(job.Task) {
+		// a rejected task was never accepted, it leaves no trace in the list
+		log.Printf("[RPC] (%d) task queue is full, rejecting", job.Task.Id)
+		cancel()
+		s.jobs.Remove(job.Task.Id)
+		return nil, status.Error(codes.ResourceExhausted, "task queue is full")
 	}
 
-	s.workerPool.SubmitTask(task)
+	go func() {
+		job.Wait(CompleteSignal)
+		cancel()
+	}()
+	return job, nil
+}
+
+func (s *CoderServiceServer) lookup(taskId int64) (*Job, error) {
+	job, ok := s.jobs.Get(int(taskId))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %d not found", taskId)
+	}
+	return job, nil
+}
+
+// storedInfo falls back to the task store when a task is not live anymore,
+// notLive is returned when the store does not know it either.
+func (s *CoderServiceServer) storedInfo(taskId int64, notLive error) (*pb.TaskInfo, error) {
+	record, err := s.jobs.Record(int(taskId))
+	if err == store.ErrNotFound {
+		return nil, notLive
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read task %d: %v", taskId, err)
+	}
+	return record.Info(), nil
+}
+
+// attach replays the job's events to stream and follows it until it
+// finishes, a task failed by an error ends the stream with its status.
+func (s *CoderServiceServer) attach(job *Job, stream grpc.ServerStreamingServer[pb.CodeResponse]) error {
+	history, subscriber := job.Subscribe()
+	defer job.Unsubscribe(subscriber)
+
+	for _, event := range history {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-subscriber:
+			if !ok {
+				select {
+				case <-job.Done():
+					log.Printf("[WORKER] (%d) finished", job.Task.Id)
+					return job.Err()
+				default:
+					return status.Error(codes.ResourceExhausted, "client fell behind the event stream, attach again to replay it")
+				}
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			log.Printf("[RPC] client detached from task (%d)", job.Task.Id)
+			return stream.Context().Err()
+		}
+	}
+}
+
+// StreamData answers a free-form query with a streamed LLM reply, no code is executed.
+func (s *StreamServiceServer) StreamData(req *pb.StreamRequest, stream pb.StreamService_StreamDataServer) error {
+	CompleteSignal := make(chan bool, 1)
+
+	provider, err := providerFor(req.Provider)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	streamLogger := log.New(logger.NewStreamWriter(stream), "", 0)
+	failure := &chatFailure{}
+
+	task := types.Task{
+		Id:             GenerateRandomID(),
+		Kind:           types.TaskKindChat,
+		CompleteSignal: CompleteSignal,
+		SystemPrompt:   req.SystemPrompt,
+		UserPrompt:     req.Query,
+		LLMProvider:    provider,
+		LLMModel:       modelOrDefault(req.LLMModel),
+		Logger:         streamLogger,
+		Events:         failure,
+		Context:        ctx,
+		Cancel:         cancel,
+	}
+
+	if !s.workerPool.TrySubmitTask(task) {
+		log.Printf("[RPC] (%d) task queue is full, rejecting", task.Id)
+		return status.Error(codes.ResourceExhausted, "task queue is full")
+	}
 	select {
 	case <-CompleteSignal:
 		log.Printf("[WORKER] (%d) finished", task.Id)
+		if event := failure.event(); event != nil {
+			return taskError(event)
+		}
 		return nil
 	case <-ctx.Done():
 		log.Printf("[RPC] client disconnected, cancelling task (%d)", task.Id)
@@ -51,24 +247,80 @@ func (s *CoderServiceServer) ExecuteCode(req *pb.CodeRequest, stream pb.CoderSer
 	}
 }
 
+func modelOrDefault(model string) string {
+	if model != "" {
+		return model
+	}
+	return config.GetString("llm.defaultModel", "gpt-3.5-turbo")
+}
+
+// providerFor returns the provider a request names, or the default one,
+// after checking it is one of config.toml.
+func providerFor(provider string) (string, error) {
+	if provider == "" {
+		provider = llm.DefaultProvider()
+	}
+	if _, err := llm.Load(provider); err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	return provider, nil
+}
+
 func StartRPCServer() {
 
-	lis, err := net.Listen("tcp", ":50051")
+	taskStore, err := store.New()
+	if err != nil {
+		log.Fatalf("Failed to open task store: %v", err)
+	}
+	err = store.Recover(taskStore, func(record *store.TaskRecord) {
+		log.Printf("[STORE] (%d) marking orphaned task as failed", record.Id)
+		if executorBackend() != "docker" {
+			return
+		}
+		if err := dockerexecutor.RemoveContainer(record.ContainerName); err != nil {
+			log.Printf("[STORE] (%d) failed to remove container %s: %v", record.Id, record.ContainerName, err)
+		}
+	})
+	if err != nil {
+		log.Printf("[STORE] failed to recover orphaned tasks: %v", err)
+	}
+
+	exec, egress := newExecutor()
+	// the API is unauthenticated, keep it off the network sandboxed code is on
+	addr := config.GetString("app.listen", ":50051")
+	if err := checkListen(addr, egress); err != nil {
+		log.Fatalf("Refusing to listen: %v", err)
+	}
+	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
+	lis = guardListener(lis, egress)
 
-	workerPool := NewWorkerPool(2)
+	workerPool := NewWorkerPool(2, exec)
 	defer workerPool.Close()
 
-	grpcServer := grpc.NewServer()
+	grpcServer := NewGRPCServer(workerPool, NewJobRegistry(taskStore))
 
-	pb.RegisterCoderServiceServer(grpcServer, &CoderServiceServer{
-		workerPool: workerPool,
-	})
-
-	log.Println("gRPC server running on port 50051...")
+	log.Printf("gRPC server running on %s...", addr)
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("Failed to serve: %v", err)
 	}
 }
+
+// NewGRPCServer returns a server with the coder and stream services
+// registered on top of workerPool.
+func NewGRPCServer(workerPool *WorkerPoolAdapter, jobs *JobRegistry) *grpc.Server {
+	// requests carry seed archives, leave room above the archive limit for the rest
+	maxRequest := (config.GetInt("workspace.maxSeedMB", 64) + 1) << 20
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(maxRequest))
+
+	pb.RegisterCoderServiceServer(grpcServer, &CoderServiceServer{
+		workerPool: workerPool,
+		jobs:       jobs,
+	})
+	pb.RegisterStreamServiceServer(grpcServer, &StreamServiceServer{
+		workerPool: workerPool,
+	})
+	return grpcServer
+}
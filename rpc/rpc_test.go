@@ -0,0 +1,786 @@
+package rpc
+
+import (
+	"archive/tar"
+	"bytes"
+	"codexec/config"
+	dockerexecutor "codexec/lib/dockerExecutor"
+	"codexec/lib/events"
+	"codexec/lib/executor"
+	"codexec/lib/llm"
+	"codexec/lib/store"
+	pb "codexec/protos/go"
+	"codexec/types"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pelletier/go-toml"
+	"github.com/tmc/langchaingo/llms"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const codeReply = "Run this:\n```bash\necho hello\n```\n"
+
+// harness is a coder service on an in-memory listener, backed by a
+// scripted LLM and a fake executor.
+type harness struct {
+	client   pb.CoderServiceClient
+	stream   pb.StreamServiceClient
+	llm      *llm.Fake
+	executor *executor.Fake
+}
+
+func newHarness(t *testing.T, script []string, results ...executor.Response) *harness {
+	t.Helper()
+
+	tree, err := toml.TreeFromMap(map[string]interface{}{
+		"app": map[string]interface{}{"codingDirectory": t.TempDir() + "/"},
+		"llm": map[string]interface{}{"providers": map[string]interface{}{"scripted": map[string]interface{}{}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Data = tree
+
+	h := &harness{
+		llm:      llm.NewFake(script...),
+		executor: executor.NewFake(results...),
+	}
+	llm.Register("scripted", func(model string, settings llm.Settings) (llms.Model, error) {
+		return h.llm, nil
+	})
+
+	workerPool := NewWorkerPool(1, h.executor)
+	server := NewGRPCServer(workerPool, NewJobRegistry(store.NewMemoryStore()))
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Stop()
+		workerPool.Close()
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	h.client = pb.NewCoderServiceClient(conn)
+	h.stream = pb.NewStreamServiceClient(conn)
+	return h
+}
+
+func request(maxRetry int32) *pb.CodeRequest {
+	return &pb.CodeRequest{
+		SystemPrompt: "You are a coder",
+		UserPrompt:   "say hello",
+		DockerImage:  "code.buildpack.python",
+		MaxRetry:     maxRetry,
+		Provider:     "scripted",
+	}
+}
+
+type stream interface {
+	Recv() (*pb.CodeResponse, error)
+}
+
+// collect reads the stream to the end and returns the typed events, log
+// lines are skipped.
+func collect(t *testing.T, events stream) []*pb.CodeResponse {
+	t.Helper()
+
+	typed, err := collectFailed(events)
+	if err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+	return typed
+}
+
+// collectFailed is collect for a stream that may end with an error status.
+func collectFailed(events stream) ([]*pb.CodeResponse, error) {
+	var typed []*pb.CodeResponse
+	for {
+		event, err := events.Recv()
+		if err == io.EOF {
+			return typed, nil
+		}
+		if err != nil {
+			return typed, err
+		}
+		if event.Event != nil {
+			typed = append(typed, event)
+		}
+	}
+}
+
+func kinds(events []*pb.CodeResponse) []string {
+	var names []string
+	for _, event := range events {
+		names = append(names, string(event.ProtoReflect().WhichOneof(event.ProtoReflect().Descriptor().Oneofs().ByName("event")).Name()))
+	}
+	return names
+}
+
+func assertKinds(t *testing.T, events []*pb.CodeResponse, want ...string) {
+	t.Helper()
+
+	got := kinds(events)
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestExecuteCodeTerminates(t *testing.T) {
+	h := newHarness(t, []string{codeReply, "hello was printed. TERMINATE"},
+		executor.Response{ExitCode: 0, Stdout: "hello\n"})
+
+	events, err := h.client.ExecuteCode(context.Background(), request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+
+	assertKinds(t, got,
+		"status", "llmMessage", "fileExtracted", "commandStarted", "output", "commandExited",
+		"retry", "feedback", "llmMessage", "terminated", "summary", "status")
+
+	if reason := got[9].GetTerminated().Reason; reason != "TERMINATE" {
+		t.Errorf("terminated reason = %q", reason)
+	}
+	summary := got[10].GetSummary()
+	if summary.Rounds != 2 {
+		t.Errorf("summary rounds = %d, want 2", summary.Rounds)
+	}
+	// the fake reports no usage, so tokens are counted locally and the
+	// second round resends the first prompt
+	if len(summary.RoundUsage) != 2 || summary.RoundUsage[1].PromptTokens <= summary.RoundUsage[0].PromptTokens {
+		t.Errorf("round usage = %v", summary.RoundUsage)
+	}
+	if !summary.Usage.Counted || summary.LlmTokens != summary.Usage.TotalTokens {
+		t.Errorf("usage = %v, llmTokens = %d", summary.Usage, summary.LlmTokens)
+	}
+	if summary.Outcome != pb.Outcome_OUTCOME_TERMINATED || summary.ExitCode != 0 {
+		t.Errorf("outcome = %v, exit code = %d", summary.Outcome, summary.ExitCode)
+	}
+	if len(summary.Files) != 1 || summary.Files[0] != "codeblock_1.sh" {
+		t.Errorf("files = %v", summary.Files)
+	}
+	if status := got[11].GetStatus(); status != pb.TaskStatus_COMPLETED {
+		t.Errorf("final status = %v", status)
+	}
+	if len(h.executor.Runs()) != 1 {
+		t.Errorf("executor ran %d times, want 1", len(h.executor.Runs()))
+	}
+	if released := h.executor.Released(); len(released) != 1 {
+		t.Errorf("released containers = %v, want the task's one", released)
+	}
+}
+
+func TestExecuteCodeStopsAtMaxRetry(t *testing.T) {
+	h := newHarness(t, []string{codeReply, codeReply, codeReply},
+		executor.Response{ExitCode: 1, Stdout: "boom"})
+
+	events, err := h.client.ExecuteCode(context.Background(), request(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+
+	var retries []*pb.Retry
+	var terminated *pb.Terminated
+	var summary *pb.Summary
+	for _, event := range got {
+		if retry := event.GetRetry(); retry != nil {
+			retries = append(retries, retry)
+		}
+		if event.GetTerminated() != nil {
+			terminated = event.GetTerminated()
+		}
+		if event.GetSummary() != nil {
+			summary = event.GetSummary()
+		}
+	}
+	if len(retries) != 1 || retries[0].ExitCode != 1 {
+		t.Errorf("retries = %v, want one after exit code 1", retries)
+	}
+	if terminated == nil || terminated.Reason != "max retries" {
+		t.Errorf("terminated = %v, want max retries", terminated)
+	}
+	if len(h.executor.Runs()) != 2 {
+		t.Errorf("executor ran %d times, want 2", len(h.executor.Runs()))
+	}
+	if summary.Outcome != pb.Outcome_OUTCOME_MAX_RETRIES || summary.ExitCode != 1 || summary.Rounds != 2 {
+		t.Errorf("summary = %v", summary)
+	}
+
+	// the failure is fed back to the model on the second round
+	calls := h.llm.Calls()
+	last := calls[len(calls)-1]
+	feedback := last[len(last)-1].Parts[0].(llms.TextContent).Text
+	if feedback != "Give me another example with modification, stdout received : boom" {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestExecutorErrorFailsTask(t *testing.T) {
+	h := newHarness(t, []string{codeReply, "TERMINATE"})
+	h.executor.Fail(executor.ExecutorError{
+		Kind: executor.ErrImageNotFound,
+		Code: "docker:container:create",
+		Err:  errors.New("No such image: code.buildpack.python"),
+	})
+
+	events, err := h.client.ExecuteCode(context.Background(), request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := collectFailed(events)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("stream ended with %v, want NotFound", err)
+	}
+	assertKinds(t, got, "status", "llmMessage", "fileExtracted", "error", "summary", "status")
+	if code := got[3].GetError().Code; code != "executor:image_not_found" {
+		t.Errorf("error code = %q", code)
+	}
+	if outcome := got[4].GetSummary().Outcome; outcome != pb.Outcome_OUTCOME_ERROR {
+		t.Errorf("outcome = %v, want ERROR", outcome)
+	}
+	if status := got[5].GetStatus(); status != pb.TaskStatus_FAILED {
+		t.Errorf("final status = %v, want FAILED", status)
+	}
+	if len(h.llm.Calls()) != 1 {
+		t.Errorf("the model was asked %d times, want once", len(h.llm.Calls()))
+	}
+	if released := h.executor.Released(); len(released) != 1 {
+		t.Errorf("released containers = %v, want the task's one", released)
+	}
+}
+
+func TestToolsModeFinishes(t *testing.T) {
+	h := newHarness(t, []string{
+		`tool:list_dir {"path": "."}`,
+		`tool:write_file {"path": "src/hello.sh", "content": "echo hello\n"}`,
+		`tool:write_file {"path": "../escape.sh", "content": "echo no\n"}`,
+		`tool:run_command {"command": "sh src/hello.sh"}`,
+		"All done.",
+		`tool:finish {"result": "printed hello"}`,
+	}, executor.Response{ExitCode: 0, Stdout: "hello\n"})
+
+	req := request(0)
+	req.Mode = pb.AgentMode_AGENT_MODE_TOOLS
+	events, err := h.client.ExecuteCode(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+
+	assertKinds(t, got,
+		"status",
+		"toolCall", "toolResult",
+		"toolCall", "fileExtracted", "toolResult",
+		"toolCall", "toolResult",
+		"toolCall", "commandStarted", "output", "commandExited", "toolResult",
+		"llmMessage", "feedback",
+		"toolCall", "terminated", "summary", "status")
+
+	if result := got[2].GetToolResult(); result.Content != "the directory is empty" || result.Failed {
+		t.Errorf("list_dir result = %v", result)
+	}
+	if result := got[7].GetToolResult(); !result.Failed || !strings.Contains(result.Content, "leaves the workspace") {
+		t.Errorf("escaping write_file result = %v", result)
+	}
+	if result := got[12].GetToolResult(); result.Content != "exit_code - 0, stdout received : hello\n" {
+		t.Errorf("run_command result = %q", result.Content)
+	}
+	if terminated := got[16].GetTerminated(); terminated.Reason != "finish" || terminated.Result != "printed hello" {
+		t.Errorf("terminated = %v", terminated)
+	}
+	summary := got[17].GetSummary()
+	if summary.Outcome != pb.Outcome_OUTCOME_TERMINATED || summary.Rounds != 6 {
+		t.Errorf("summary = %v", summary)
+	}
+	if len(summary.Files) != 1 || summary.Files[0] != "src/hello.sh" {
+		t.Errorf("files = %v", summary.Files)
+	}
+
+	runs := h.executor.Runs()
+	if len(runs) != 1 || len(runs[0].Commands) != 1 || runs[0].Commands[0] != "sh src/hello.sh" {
+		t.Errorf("executor runs = %+v", runs)
+	}
+	// every call is answered with its own tool message
+	calls := h.llm.Calls()
+	last := calls[len(calls)-1]
+	response, ok := last[len(last)-3].Parts[0].(llms.ToolCallResponse)
+	if !ok || response.ToolCallID != "call_4" || response.Name != "run_command" {
+		t.Errorf("run_command response = %+v", last[len(last)-3].Parts[0])
+	}
+}
+
+func TestSubmitAttachAndGet(t *testing.T) {
+	h := newHarness(t, []string{"TERMINATE"})
+	ctx := context.Background()
+
+	info, err := h.client.SubmitTask(ctx, request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// attaching twice replays the same history
+	attached, err := h.client.AttachTask(ctx, &pb.TaskQuery{TaskId: info.TaskId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := collect(t, attached)
+	attached, err = h.client.AttachTask(ctx, &pb.TaskQuery{TaskId: info.TaskId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertKinds(t, collect(t, attached), kinds(first)...)
+
+	info, err = h.client.GetTask(ctx, &pb.TaskQuery{TaskId: info.TaskId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != pb.TaskStatus_COMPLETED {
+		t.Errorf("status = %v, want COMPLETED", info.Status)
+	}
+
+	list, err := h.client.ListTasks(ctx, &pb.ListTasksRequest{Status: []pb.TaskStatus{pb.TaskStatus_COMPLETED}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].TaskId != info.TaskId {
+		t.Errorf("listed tasks = %v", list.Tasks)
+	}
+}
+
+func TestCancelTask(t *testing.T) {
+	blocked := make(chan struct{})
+	h := newHarness(t, nil)
+	llm.Register("scripted", func(model string, settings llm.Settings) (llms.Model, error) {
+		return blockingModel{blocked}, nil
+	})
+	ctx := context.Background()
+
+	info, err := h.client.SubmitTask(ctx, request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-blocked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task never reached the model")
+	}
+
+	if _, err := h.client.CancelTask(ctx, &pb.TaskQuery{TaskId: info.TaskId}); err != nil {
+		t.Fatal(err)
+	}
+	attached, err := h.client.AttachTask(ctx, &pb.TaskQuery{TaskId: info.TaskId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, attached)
+	if status := got[len(got)-1].GetStatus(); status != pb.TaskStatus_CANCELLED {
+		t.Errorf("final status = %v, want CANCELLED", status)
+	}
+}
+
+// blockingModel waits for the request context to be cancelled.
+type blockingModel struct {
+	started chan struct{}
+}
+
+func (m blockingModel) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	close(m.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m blockingModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func download(t *testing.T, chunks interface {
+	Recv() (*pb.FileChunk, error)
+}) (string, []byte, error) {
+	t.Helper()
+
+	var name string
+	var data bytes.Buffer
+	for {
+		chunk, err := chunks.Recv()
+		if err == io.EOF {
+			return name, data.Bytes(), nil
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if chunk.Name != "" {
+			name = chunk.Name
+		}
+		data.Write(chunk.Data)
+	}
+}
+
+func TestWorkspaceDownload(t *testing.T) {
+	h := newHarness(t, []string{codeReply, "TERMINATE"})
+	ctx := context.Background()
+
+	info, err := h.client.SubmitTask(ctx, request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attached, err := h.client.AttachTask(ctx, &pb.TaskQuery{TaskId: info.TaskId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collect(t, attached)
+
+	listing, err := h.client.ListWorkspace(ctx, &pb.TaskQuery{TaskId: info.TaskId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listing.Files) != 1 || listing.Files[0].Path != "codeblock_1.sh" {
+		t.Fatalf("listing = %v", listing.Files)
+	}
+
+	chunks, err := h.client.DownloadFile(ctx, &pb.FileRequest{TaskId: info.TaskId, Path: "codeblock_1.sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, data, err := download(t, chunks)
+	if err != nil || name != "codeblock_1.sh" || string(data) != "echo hello\n" {
+		t.Errorf("download = %q, %q, %v", name, data, err)
+	}
+
+	chunks, err = h.client.DownloadFile(ctx, &pb.FileRequest{TaskId: info.TaskId, Path: "../../etc/passwd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := download(t, chunks); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("escaping download error = %v, want InvalidArgument", err)
+	}
+
+	chunks, err = h.client.DownloadWorkspace(ctx, &pb.ArchiveRequest{TaskId: info.TaskId, Format: pb.ArchiveFormat_TAR_GZ})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, data, err = download(t, chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, err := tar.NewReader(gz).Next()
+	if err != nil || header.Name != "codeblock_1.sh" {
+		t.Errorf("archive entry = %v, %v", header, err)
+	}
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestSeedFromArchive(t *testing.T) {
+	h := newHarness(t, []string{"TERMINATE"})
+
+	req := request(3)
+	req.Seed = &pb.Seed{
+		Source:        &pb.Seed_Archive{Archive: tarGz(t, map[string]string{"data/input.csv": "a,b\n1,2\n"})},
+		ArchiveFormat: pb.ArchiveFormat_TAR_GZ,
+	}
+	events, err := h.client.ExecuteCode(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+	assertKinds(t, got, "status", "seeded", "llmMessage", "terminated", "summary", "status")
+
+	seeded := got[1].GetSeeded()
+	if seeded.Files != 1 || seeded.Size != 8 {
+		t.Errorf("seeded = %v", seeded)
+	}
+	if files := got[4].GetSummary().Files; len(files) != 1 || files[0] != "data/input.csv" {
+		t.Errorf("summary files = %v", files)
+	}
+	prompt := h.llm.Calls()[0][1].Parts[0].(llms.TextContent).Text
+	if !strings.Contains(prompt, "- data/input.csv") {
+		t.Errorf("first prompt does not list the seeded files: %q", prompt)
+	}
+}
+
+func TestSeedRejectsEscapingArchive(t *testing.T) {
+	h := newHarness(t, []string{"TERMINATE"})
+
+	req := request(3)
+	req.Seed = &pb.Seed{
+		Source:        &pb.Seed_Archive{Archive: tarGz(t, map[string]string{"../escaped.txt": "x"})},
+		ArchiveFormat: pb.ArchiveFormat_TAR_GZ,
+	}
+	events, err := h.client.ExecuteCode(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := collectFailed(events)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("stream ended with %v, want InvalidArgument", err)
+	}
+	assertKinds(t, got, "status", "error", "summary", "status")
+	if got[3].GetStatus() != pb.TaskStatus_FAILED {
+		t.Errorf("status = %v, want FAILED", got[3].GetStatus())
+	}
+	if len(h.llm.Calls()) != 0 {
+		t.Error("the model was asked although seeding failed")
+	}
+
+	req.Seed = &pb.Seed{Source: &pb.Seed_GitRepository{GitRepository: "project.git"}}
+	events, err = h.client.ExecuteCode(context.Background(), req)
+	if err == nil {
+		_, err = events.Recv()
+	}
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("git seed without gitRoot = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestCommandTimeoutIsReported(t *testing.T) {
+	h := newHarness(t, []string{codeReply, "TERMINATE"},
+		executor.Response{ExitCode: executor.TimeoutExitCode, TimedOut: true})
+
+	events, err := h.client.ExecuteCode(context.Background(), request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+	for _, event := range got {
+		if exited := event.GetCommandExited(); exited != nil && !exited.TimedOut {
+			t.Errorf("command exit %v not marked as timed out", exited)
+		}
+		if feedback := event.GetFeedback(); feedback != nil && !strings.Contains(feedback.Content, "was killed") {
+			t.Errorf("feedback = %q", feedback.Content)
+		}
+	}
+	if limits := h.executor.Runs()[0].Limits; limits.CommandTimeout != 120*time.Second || limits.MemoryMB != 512 {
+		t.Errorf("default limits = %+v", limits)
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	h := newHarness(t, nil)
+	llm.Register("scripted", func(model string, settings llm.Settings) (llms.Model, error) {
+		return blockingModel{make(chan struct{})}, nil
+	})
+
+	req := request(3)
+	req.Limits = &pb.Limits{TaskTimeoutSeconds: 1}
+	events, err := h.client.ExecuteCode(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+	assertKinds(t, got, "status", "terminated", "summary", "status")
+	if outcome := got[2].GetSummary().Outcome; outcome != pb.Outcome_OUTCOME_TIMED_OUT {
+		t.Errorf("outcome = %v, want TIMED_OUT", outcome)
+	}
+	if status := got[3].GetStatus(); status != pb.TaskStatus_FAILED {
+		t.Errorf("final status = %v, want FAILED", status)
+	}
+}
+
+func TestNetworkPolicy(t *testing.T) {
+	h := newHarness(t, []string{codeReply, "TERMINATE"})
+
+	events, err := h.client.ExecuteCode(context.Background(), request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := collect(t, events)
+	if network := got[len(got)-2].GetSummary().Network; network != pb.NetworkPolicy_NETWORK_NONE {
+		t.Errorf("summary network = %v, want NONE by default", network)
+	}
+
+	req := request(3)
+	req.Network = pb.NetworkPolicy_NETWORK_FULL
+	events, err = h.client.ExecuteCode(context.Background(), req)
+	if err == nil {
+		_, err = events.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("full network above the default ceiling = %v, want PermissionDenied", err)
+	}
+}
+
+func TestStderrIsStreamedAndFedBack(t *testing.T) {
+	h := newHarness(t, []string{codeReply, "TERMINATE"},
+		executor.Response{ExitCode: 1, Stdout: "partial\n", Stderr: "NameError: name 'x' is not defined\n"})
+
+	events, err := h.client.ExecuteCode(context.Background(), request(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stderr, feedback string
+	for _, event := range collect(t, events) {
+		if output := event.GetOutput(); output != nil && output.Stream == pb.OutputStream_STDERR {
+			stderr += output.Data
+		}
+		if event.GetFeedback() != nil {
+			feedback = event.GetFeedback().Content
+		}
+	}
+	if !strings.Contains(stderr, "NameError") {
+		t.Errorf("stderr output = %q", stderr)
+	}
+	if !strings.Contains(feedback, "stdout received : partial") || !strings.Contains(feedback, "stderr received : NameError") {
+		t.Errorf("feedback = %q", feedback)
+	}
+}
+
+func TestListenAddressIsKeptOffTheEgressGateway(t *testing.T) {
+	egress := dockerexecutor.Egress{Network: "codexec-egress", Gateway: "172.18.0.1"}
+	for addr, allowed := range map[string]bool{
+		"127.0.0.1:50051":  true,
+		"10.0.0.5:50051":   true,
+		":50051":           true,
+		"0.0.0.0:50051":    true,
+		"[::]:50051":       true,
+		"172.18.0.1:50051": false,
+	} {
+		if err := checkListen(addr, egress); (err == nil) != allowed {
+			t.Errorf("checkListen(%s) = %v", addr, err)
+		}
+	}
+	if err := checkListen("172.18.0.1:50051", dockerexecutor.Egress{}); err != nil {
+		t.Errorf("without egress network checkListen = %v", err)
+	}
+}
+
+func TestConnectionsThroughTheEgressGatewayAreDropped(t *testing.T) {
+	// the loopback address stands in for the gateway
+	for gateway, served := range map[string]bool{"127.0.0.1": false, "172.18.0.1": true} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		guarded := guardListener(lis, dockerexecutor.Egress{Network: "codexec-egress", Gateway: gateway})
+		accepted := make(chan net.Conn, 1)
+		go func() {
+			if conn, err := guarded.Accept(); err == nil {
+				conn.Write([]byte("ok"))
+				accepted <- conn
+			}
+		}()
+
+		conn, err := net.Dial("tcp", lis.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		reply, _ := io.ReadAll(io.LimitReader(conn, 2))
+		if got := string(reply) == "ok"; got != served {
+			t.Errorf("gateway %s: served = %t, want %t", gateway, got, served)
+		}
+		conn.Close()
+		lis.Close()
+		select {
+		case accepted := <-accepted:
+			accepted.Close()
+		default:
+		}
+	}
+}
+
+func TestStreamDataFailsWithTheModel(t *testing.T) {
+	h := newHarness(t, nil)
+
+	stream, err := h.stream.StreamData(context.Background(), &pb.StreamRequest{Query: "hi", Provider: "scripted", LLMModel: "fake"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+	}
+	if status.Code(err) != codes.Unavailable || !strings.Contains(err.Error(), "llm:generate") {
+		t.Errorf("stream ended with %v, want Unavailable llm:generate", err)
+	}
+}
+
+func TestJobHistoryKeepsTheEndOfTheOutput(t *testing.T) {
+	tree, _ := toml.TreeFromMap(map[string]interface{}{
+		"jobs": map[string]interface{}{"maxReplayOutputKB": 1},
+	})
+	config.Data = tree
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	job := NewJobRegistry(store.NewMemoryStore()).Create(types.Task{Context: ctx, Cancel: cancel})
+
+	job.Emit(events.Status(pb.TaskStatus_RUNNING))
+	for i := 0; i < 100; i++ {
+		job.Emit(events.Output(0, pb.OutputStream_STDOUT, fmt.Sprintf("line %03d .......\n", i)))
+	}
+	history, _ := job.Subscribe()
+
+	if len(history) != 3 || history[0].GetStatus() != pb.TaskStatus_RUNNING {
+		t.Fatalf("history has %d events, want the status, a note and one merged chunk", len(history))
+	}
+	note, output := history[1].GetOutput().GetData(), history[2].GetOutput().GetData()
+	if !strings.Contains(note, "bytes of earlier output dropped") {
+		t.Errorf("note = %q", note)
+	}
+	if len(output) != 1024 || !strings.HasSuffix(output, "line 099 .......\n") {
+		t.Errorf("output is %d bytes ending in %q", len(output), output[len(output)-20:])
+	}
+}
+
+func TestUnknownProviderIsRejected(t *testing.T) {
+	h := newHarness(t, nil)
+
+	req := request(1)
+	req.Provider = "scripted.apiKey"
+	events, err := h.client.ExecuteCode(context.Background(), req)
+	if err == nil {
+		_, err = events.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unknown provider = %v, want InvalidArgument", err)
+	}
+}
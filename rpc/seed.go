@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"bytes"
+	"codexec/config"
+	"codexec/lib"
+	pb "codexec/protos/go"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxListedFiles caps how many seeded files are named in the first prompt.
+const maxListedFiles = 50
+
+// checkSeed rejects a seed that can never be applied before its task is queued.
+func checkSeed(seed *pb.Seed) error {
+	switch source := seed.GetSource().(type) {
+	case *pb.Seed_Archive:
+		if seed.ArchiveFormat != pb.ArchiveFormat_TAR_GZ && seed.ArchiveFormat != pb.ArchiveFormat_ZIP {
+			return status.Errorf(codes.InvalidArgument, "unknown archive format %v", seed.ArchiveFormat)
+		}
+	case *pb.Seed_GitRepository:
+		_, err := gitRepositoryPath(source.GitRepository)
+		return err
+	}
+	return nil
+}
+
+// gitRepositoryPath resolves a repository name below `workspace.gitRoot`,
+// seeding from git is disabled while that is not set.
+func gitRepositoryPath(name string) (string, error) {
+	root := config.GetString("workspace.gitRoot", "")
+	if root == "" {
+		return "", status.Error(codes.FailedPrecondition, "seeding from git is disabled, workspace.gitRoot is not set")
+	}
+	path, err := lib.ConfinedPath(root, name)
+	if os.IsNotExist(err) {
+		return "", status.Errorf(codes.NotFound, "git repository %s not found", name)
+	}
+	if err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return path, nil
+}
+
+// seedSource describes a seed for logs and the task record.
+func seedSource(seed *pb.Seed) string {
+	switch source := seed.GetSource().(type) {
+	case *pb.Seed_Archive:
+		return fmt.Sprintf("archive (%s, %d bytes)", strings.ToLower(seed.ArchiveFormat.String()), len(source.Archive))
+	case *pb.Seed_GitRepository:
+		if seed.GitRef != "" {
+			return fmt.Sprintf("git %s@%s", source.GitRepository, seed.GitRef)
+		}
+		return "git " + source.GitRepository
+	}
+	return ""
+}
+
+// seedWorkspace fills the workspace dir of a task with the files of seed.
+func seedWorkspace(ctx context.Context, dir string, seed *pb.Seed) (*pb.WorkspaceSeeded, error) {
+	limit := int64(config.GetInt("workspace.maxSeedMB", 64)) << 20
+	seeded := &pb.WorkspaceSeeded{Source: seedSource(seed)}
+
+	switch source := seed.GetSource().(type) {
+	case *pb.Seed_Archive:
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, err
+		}
+		var files int
+		var size int64
+		var err error
+		if seed.ArchiveFormat == pb.ArchiveFormat_ZIP {
+			files, size, err = lib.ExtractZip(bytes.NewReader(source.Archive), int64(len(source.Archive)), dir, limit)
+		} else {
+			files, size, err = lib.ExtractTarGz(bytes.NewReader(source.Archive), dir, limit)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract archive: %v", err)
+		}
+		seeded.Files, seeded.Size = int32(files), size
+	case *pb.Seed_GitRepository:
+		repository, err := gitRepositoryPath(source.GitRepository)
+		if err != nil {
+			return nil, err
+		}
+		if err := lib.CloneRepository(ctx, repository, seed.GitRef, dir); err != nil {
+			return nil, err
+		}
+		files, size, err := lib.CountFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if size > limit {
+			return nil, fmt.Errorf("repository content exceeds %d bytes", limit)
+		}
+		seeded.Files, seeded.Size = int32(files), size
+	}
+	return seeded, nil
+}
+
+// withWorkspaceFiles tells the model which files it can build upon.
+func withWorkspaceFiles(prompt string, files []string) string {
+	if len(files) == 0 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString(prompt)
+	b.WriteString("\n\nThe working directory already contains these files:\n")
+	for i, file := range files {
+		if i == maxListedFiles {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(files)-maxListedFiles)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+	return b.String()
+}
@@ -4,7 +4,11 @@ import (
 	"codexec/config"
 	"codexec/lib"
 	"codexec/lib/agent"
+	"codexec/lib/events"
+	"codexec/lib/executor"
+	pb "codexec/protos/go"
 	"codexec/types"
+	"context"
 	"fmt"
 	"log"
 	"math/rand"
@@ -13,17 +17,20 @@ import (
 
 type WorkerPoolAdapter struct {
 	types.WorkerPool
+	executor executor.Executor
 }
 
 type TaskAdapter struct {
 	types.Task
 }
 
-func NewWorkerPool(numWorkers int) *WorkerPoolAdapter {
+// NewWorkerPool starts numWorkers workers running coding tasks with executor.
+func NewWorkerPool(numWorkers int, executor executor.Executor) *WorkerPoolAdapter {
 	pool := &WorkerPoolAdapter{
 		WorkerPool: types.WorkerPool{
-			Tasks: make(chan types.Task),
+			Tasks: make(chan types.Task, config.GetInt("app.queueSize", 100)),
 		},
+		executor: executor,
 	}
 	for i := 1; i <= numWorkers; i++ {
 		pool.Wg.Add(1)
@@ -41,11 +48,33 @@ func (p *WorkerPoolAdapter) SubmitTask(task types.Task) {
 	p.Tasks <- task
 }
 
+// TrySubmitTask queues task unless the queue is full.
+func (p *WorkerPoolAdapter) TrySubmitTask(task types.Task) bool {
+	select {
+	case p.Tasks <- task:
+		log.Printf("[WORKER] (%d) queued", task.Id)
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *WorkerPoolAdapter) Close() {
 	close(p.Tasks)
 	p.Wg.Wait()
 }
 
+// containerNameFor names the container of a task predictably so leftovers
+// can be found again after a restart.
+func containerNameFor(taskId int) string {
+	return fmt.Sprintf("codexec-%d", taskId)
+}
+
+// workspaceFor is the host directory holding the files of a task container.
+func workspaceFor(containerName string) string {
+	return fmt.Sprintf("%s%s", config.GetString("app.codingDirectory", "coding/"), containerName)
+}
+
 func GenerateRandomID() int {
 	rand.Seed(time.Now().UnixNano())
 	return rand.Intn(1000000) + 1
@@ -60,36 +89,101 @@ func (p *WorkerPoolAdapter) worker(workerID int) {
 		select {
 		case <-task.Context.Done():
 			log.Printf("[WORKER] client disconnected, cancelling task (%d)", task.Id)
-			break
+			task.CompleteSignal <- true
 		default:
 			log.Printf("[WORKER] (%d) running", task.Id)
-			containerName := lib.GetContainerName(12)
-			// Creating a blank dir
-			// hostDir := fmt.Sprintf("%s%s", config.Data.Get("app.codingDirectory").(string), task.workingDirectory)
-			hostDir := fmt.Sprintf("%s%s", config.Data.Get("app.codingDirectory").(string), containerName)
-
-			coder := agent.New()
-
-			coder = &agent.AgentAdapter{
-				CoderAgent: types.CoderAgent{
-					SystemPrompt:        task.SystemPrompt,
-					UserPrompt:          task.UserPrompt,
-					DockerImage:         task.DockerImage,
-					DockerContainerName: containerName,
-					LLMModel:            task.LLMModel,
-					MaxRetry:            task.MaxRetry,
-					WorkingDirectory:    hostDir,
-					MaxTimeOut:          1,
-					Logger:              task.Logger,
-					Instrumentation:     types.InstrumentationStats{},
-					Context:             task.Context,
-					Cancel:              task.Cancel,
-					Task:                &task,
-				},
+			switch task.Kind {
+			case types.TaskKindChat:
+				p.answer(task)
+			default:
+				p.code(task)
 			}
-			coder.StartTimer()
-			coder.Run()
-			coder.EndTimer()
+			task.CompleteSignal <- true
 		}
 	}
 }
+
+func (p *WorkerPoolAdapter) code(task types.Task) {
+	task.Events.Emit(events.Status(pb.TaskStatus_RUNNING))
+	containerName := task.ContainerName
+	hostDir := workspaceFor(containerName)
+
+	ctx := task.Context
+	if task.Limits.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, task.Limits.TaskTimeout)
+		defer cancel()
+	}
+
+	userPrompt := task.UserPrompt
+	if task.Seed != nil {
+		seeded, err := seedWorkspace(ctx, hostDir, task.Seed)
+		if err != nil {
+			log.Printf("[WORKER] (%d) failed to seed workspace: %v", task.Id, err)
+			task.Events.Emit(events.Error("workspace:seed", err.Error()))
+			task.Events.Emit(events.Summary(types.InstrumentationStats{Outcome: pb.Outcome_OUTCOME_ERROR}))
+			return
+		}
+		task.Events.Emit(events.Seeded(seeded.Source, seeded.Files, seeded.Size))
+		files, _ := lib.ListFiles(hostDir)
+		userPrompt = withWorkspaceFiles(userPrompt, files)
+	}
+
+	coder := &agent.AgentAdapter{
+		Executor: p.executor,
+		CoderAgent: types.CoderAgent{
+			SystemPrompt:        task.SystemPrompt,
+			UserPrompt:          userPrompt,
+			DockerImage:         task.DockerImage,
+			DockerContainerName: containerName,
+			LLMProvider:         task.LLMProvider,
+			LLMModel:            task.LLMModel,
+			MaxRetry:            task.MaxRetry,
+			WorkingDirectory:    hostDir,
+			Limits:              task.Limits,
+			Network:             task.Network,
+			Buildpack:           task.Buildpack,
+			Logger:              task.Logger,
+			Events:              task.Events,
+			Instrumentation:     types.InstrumentationStats{Network: task.Network},
+			Context:             ctx,
+			Cancel:              task.Cancel,
+			Task:                &task,
+		},
+	}
+	executor.ResetLog(hostDir, containerName)
+	coder.StartTimer()
+	if task.Kind == types.TaskKindTools {
+		coder.RunTools()
+	} else {
+		coder.Run()
+	}
+	coder.EndTimer()
+	p.executor.Release(executor.Params{ContainerName: containerName, WorkingDirectory: hostDir})
+	// a workspace is only created once the model replied with code
+	if files, err := lib.ListFiles(hostDir); err == nil {
+		coder.Instrumentation.Files = files
+	}
+	task.Events.Emit(events.Summary(coder.Instrumentation))
+}
+
+func (p *WorkerPoolAdapter) answer(task types.Task) {
+	coder := &agent.AgentAdapter{
+		CoderAgent: types.CoderAgent{
+			SystemPrompt:    task.SystemPrompt,
+			UserPrompt:      task.UserPrompt,
+			LLMProvider:     task.LLMProvider,
+			LLMModel:        task.LLMModel,
+			Logger:          task.Logger,
+			Events:          task.Events,
+			Instrumentation: types.InstrumentationStats{},
+			Context:         task.Context,
+			Cancel:          task.Cancel,
+			Task:            &task,
+		},
+	}
+	coder.StartTimer()
+	coder.Answer()
+	coder.EndTimer()
+	log.Printf("[WORKER] (%d) answered, tokens: %d, estimated cost: $%.4f", task.Id, coder.Instrumentation.LLMTokens, coder.Instrumentation.Usage.EstimatedCost)
+}
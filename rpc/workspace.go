@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"bufio"
+	"codexec/lib"
+	"codexec/lib/store"
+	pb "codexec/protos/go"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chunkSize keeps download messages well below the default 4MB gRPC limit.
+const chunkSize = 64 * 1024
+
+func (s *CoderServiceServer) ListWorkspace(ctx context.Context, req *pb.TaskQuery) (*pb.WorkspaceListing, error) {
+	root, err := s.workspace(req.TaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &pb.WorkspaceListing{TaskId: req.TaskId}
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		listing.Files = append(listing.Files, &pb.WorkspaceFile{
+			Path:       filepath.ToSlash(rel),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime().UnixMilli(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list workspace: %v", err)
+	}
+	return listing, nil
+}
+
+func (s *CoderServiceServer) DownloadFile(req *pb.FileRequest, stream pb.CoderService_DownloadFileServer) error {
+	root, err := s.workspace(req.TaskId)
+	if err != nil {
+		return err
+	}
+	path, err := lib.ConfinedPath(root, req.Path)
+	if os.IsNotExist(err) {
+		return status.Errorf(codes.NotFound, "file %s not found", req.Path)
+	}
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to open %s: %v", req.Path, err)
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil || !info.Mode().IsRegular() {
+		return status.Errorf(codes.InvalidArgument, "%s is not a regular file", req.Path)
+	}
+
+	writer := newChunkWriter(stream, filepath.Base(path))
+	if _, err := io.Copy(writer, file); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+func (s *CoderServiceServer) DownloadWorkspace(req *pb.ArchiveRequest, stream pb.CoderService_DownloadWorkspaceServer) error {
+	root, err := s.workspace(req.TaskId)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("task-%d", req.TaskId)
+	switch req.Format {
+	case pb.ArchiveFormat_ZIP:
+		writer := newChunkWriter(stream, name+".zip")
+		if err := lib.WriteZip(writer, root); err != nil {
+			return status.Errorf(codes.Internal, "failed to archive workspace: %v", err)
+		}
+		return writer.Close()
+	default:
+		writer := newChunkWriter(stream, name+".tar.gz")
+		if err := lib.WriteTarGz(writer, root); err != nil {
+			return status.Errorf(codes.Internal, "failed to archive workspace: %v", err)
+		}
+		return writer.Close()
+	}
+}
+
+// workspace returns the existing workspace directory of a task.
+func (s *CoderServiceServer) workspace(taskId int64) (string, error) {
+	root, err := s.jobs.Workspace(int(taskId))
+	if err == store.ErrNotFound {
+		return "", status.Errorf(codes.NotFound, "task %d not found", taskId)
+	}
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to read task %d: %v", taskId, err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", status.Errorf(codes.NotFound, "task %d has no workspace yet", taskId)
+	}
+	return root, nil
+}
+
+// chunkWriter sends everything written to it as FileChunk messages of up
+// to chunkSize bytes.
+type chunkWriter struct {
+	*bufio.Writer
+	sender *chunkSender
+}
+
+type chunkSender struct {
+	stream grpc.ServerStreamingServer[pb.FileChunk]
+	name   string
+	sent   bool
+}
+
+func newChunkWriter(stream grpc.ServerStreamingServer[pb.FileChunk], name string) *chunkWriter {
+	sender := &chunkSender{stream: stream, name: name}
+	return &chunkWriter{Writer: bufio.NewWriterSize(sender, chunkSize), sender: sender}
+}
+
+// Close sends what is buffered, an empty file still gets its named chunk.
+func (w *chunkWriter) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !w.sender.sent {
+		return w.sender.stream.Send(&pb.FileChunk{Name: w.sender.name})
+	}
+	return nil
+}
+
+func (c *chunkSender) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), chunkSize)
+		chunk := &pb.FileChunk{Data: p[:n]}
+		if !c.sent {
+			chunk.Name = c.name
+			c.sent = true
+		}
+		if err := c.stream.Send(chunk); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
@@ -20,6 +20,7 @@ def run():
         6. You MUST wait for user to respond weather code ran successfully with exit-code 0.
         7. If user responds with exit-code with expected output only then reply with word "TERMINATE" only at the end to indicate to user that he can stop now.
         8. Do not respond with code block if expected out is returned with exit-code 0.
+        9. To change a file you already wrote, you may reply with a unified diff in a diff code block instead of the whole file.
         """
         request = coder_pb2.CodeRequest(
             systemPrompt= system_prompt,
@@ -36,8 +37,37 @@ def run():
         # Iterate through the responses from the server
         try:
             for response in response_stream:
-                # sys.stdout.write(response.data)
-                print(response.data, end='')
+                event = response.WhichOneof('event')
+                if event is None:
+                    # sys.stdout.write(response.data)
+                    print(response.data, end='')
+                elif event == 'commandStarted':
+                    print(f"$ {response.commandStarted.command}")
+                elif event == 'commandExited':
+                    exited = response.commandExited
+                    print(f"[exit code {exited.exitCode}{', timed out' if exited.timedOut else ''}]")
+                elif event == 'image':
+                    print(f"[{response.image.image}] {response.image.line}")
+                elif event == 'fileRejected':
+                    rejected = response.fileRejected
+                    print(f"[not saved {rejected.path}] {rejected.reason}")
+                    if rejected.hunk:
+                        print(rejected.hunk, end='')
+                elif event == 'toolCall':
+                    print(f"> {response.toolCall.name} {response.toolCall.arguments}")
+                elif event == 'toolResult':
+                    print(response.toolResult.content)
+                elif event == 'terminated' and response.terminated.result:
+                    print(f"result: {response.terminated.result}")
+                elif event == 'error':
+                    print(f"[error {response.error.code}] {response.error.message}")
+                elif event == 'summary':
+                    summary = response.summary
+                    usage = summary.usage
+                    print(f"outcome: {coder_pb2.Outcome.Name(summary.outcome)}, exit code: {summary.exitCode}, "
+                          f"network: {coder_pb2.NetworkPolicy.Name(summary.network)}, files: {list(summary.files)}")
+                    print(f"rounds: {summary.rounds}, tokens: {usage.promptTokens} prompt + {usage.completionTokens} completion, "
+                          f"cost: ${usage.estimatedCost:.4f}, time: {summary.timeTaken}s")
         except grpc.RpcError as e:
             print(f"RPC failed: {e.code()} - {e.details()}")
         except Exception as e:
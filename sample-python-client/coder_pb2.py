@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0b\x63oder.proto\x12\x05\x63oder\"\x8a\x01\n\x0b\x43odeRequest\x12\x14\n\x0csystemPrompt\x18\x01 \x01(\t\x12\x12\n\nuserPrompt\x18\x02 \x01(\t\x12\x18\n\x10workingDirectory\x18\x03 \x01(\t\x12\x13\n\x0b\x64ockerImage\x18\x04 \x01(\t\x12\x10\n\x08maxRetry\x18\x05 \x01(\x05\x12\x10\n\x08LLMModel\x18\x06 \x01(\t\"\x1c\n\x0c\x43odeResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\t2H\n\x0c\x43oderService\x12\x38\n\x0b\x45xecuteCode\x12\x12.coder.CodeRequest\x1a\x13.coder.CodeResponse0\x01\x42\rZ\x0b./protos/gob\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0b\x63oder.proto\x12\x05\x63oder\"\x9d\x02\n\x0b\x43odeRequest\x12\x14\n\x0csystemPrompt\x18\x01 \x01(\t\x12\x12\n\nuserPrompt\x18\x02 \x01(\t\x12\x18\n\x10workingDirectory\x18\x03 \x01(\t\x12\x13\n\x0b\x64ockerImage\x18\x04 \x01(\t\x12\x10\n\x08maxRetry\x18\x05 \x01(\x05\x12\x10\n\x08LLMModel\x18\x06 \x01(\t\x12\x10\n\x08provider\x18\x07 \x01(\t\x12\x19\n\x04seed\x18\x08 \x01(\x0b\x32\x0b.coder.Seed\x12\x1d\n\x06limits\x18\t \x01(\x0b\x32\r.coder.Limits\x12%\n\x07network\x18\n \x01(\x0e\x32\x14.coder.NetworkPolicy\x12\x1e\n\x04mode\x18\x0b \x01(\x0e\x32\x10.coder.AgentMode\"\x95\x01\n\x06Limits\x12\x0c\n\x04\x63pus\x18\x01 \x01(\x01\x12\x11\n\tcpuShares\x18\x02 \x01(\x03\x12\x10\n\x08memoryMB\x18\x03 \x01(\x03\x12\x0c\n\x04pids\x18\x04 \x01(\x03\x12\x0f\n\x07tmpfsMB\x18\x05 \x01(\x03\x12\x1d\n\x15\x63ommandTimeoutSeconds\x18\x06 \x01(\x05\x12\x1a\n\x12taskTimeoutSeconds\x18\x07 \x01(\x05\"y\n\x04Seed\x12\x11\n\x07\x61rchive\x18\x01 \x01(\x0cH\x00\x12\x17\n\rgitRepository\x18\x02 \x01(\tH\x00\x12+\n\rarchiveFormat\x18\x03 \x01(\x0e\x32\x14.coder.ArchiveFormat\x12\x0e\n\x06gitRef\x18\x04 \x01(\tB\x08\n\x06source\"\xc6\x05\n\x0c\x43odeResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\t\x12\x0e\n\x06taskId\x18\x02 \x01(\x03\x12\x11\n\ttimestamp\x18\x03 \x01(\x03\x12\'\n\nllmMessage\x18\n \x01(\x0b\x32\x11.coder.LLMMessageH\x00\x12-\n\rfileExtracted\x18\x0b \x01(\x0b\x32\x14.coder.FileExtractedH\x00\x12/\n\x0e\x63ommandStarted\x18\x0c \x01(\x0b\x32\x15.coder.CommandStartedH\x00\x12$\n\x06output\x18\r \x01(\x0b\x32\x12.coder.OutputChunkH\x00\x12-\n\rcommandExited\x18\x0e \x01(\x0b\x32\x14.coder.CommandExitedH\x00\x12\x1d\n\x05retry\x18\x0f \x01(\x0b\x32\x0c.coder.RetryH\x00\x12\'\n\nterminated\x18\x10 \x01(\x0b\x32\x11.coder.TerminatedH\x00\x12\x1d\n\x05\x65rror\x18\x11 \x01(\x0b\x32\x0c.coder.ErrorH\x00\x12!\n\x07summary\x18\x12 \x01(\x0b\x32\x0e.coder.SummaryH\x00\x12#\n\x06status\x18\x13 \x01(\x0e\x32\x11.coder.TaskStatusH\x00\x12#\n\x08\x66\x65\x65\x64\x62\x61\x63k\x18\x14 \x01(\x0b\x32\x0f.coder.FeedbackH\x00\x12(\n\x06seeded\x18\x15 \x01(\x0b\x32\x16.coder.WorkspaceSeededH\x00\x12%\n\x05image\x18\x16 \x01(\x0b\x32\x14.coder.ImageProgressH\x00\x12+\n\x0c\x66ileRejected\x18\x17 \x01(\x0b\x32\x13.coder.FileRejectedH\x00\x12#\n\x08toolCall\x18\x18 \x01(\x0b\x32\x0f.coder.ToolCallH\x00\x12\'\n\ntoolResult\x18\x19 \x01(\x0b\x32\x11.coder.ToolResultH\x00\x42\x07\n\x05\x65vent\"\x1b\n\tTaskQuery\x12\x0e\n\x06taskId\x18\x01 \x01(\x03\"\xe5\x01\n\x08TaskInfo\x12\x0e\n\x06taskId\x18\x01 \x01(\x03\x12!\n\x06status\x18\x02 \x01(\x0e\x32\x11.coder.TaskStatus\x12\x12\n\nuserPrompt\x18\x03 \x01(\t\x12\x10\n\x08LLMModel\x18\x04 \x01(\t\x12\x13\n\x0b\x64ockerImage\x18\x05 \x01(\t\x12\x11\n\tcreatedAt\x18\x06 \x01(\x03\x12\x11\n\tstartedAt\x18\x07 \x01(\x03\x12\x12\n\nfinishedAt\x18\x08 \x01(\x03\x12\x10\n\x08provider\x18\t \x01(\t\x12\x1f\n\x07outcome\x18\n \x01(\x0e\x32\x0e.coder.Outcome\"D\n\x10ListTasksRequest\x12!\n\x06status\x18\x01 \x03(\x0e\x32\x11.coder.TaskStatus\x12\r\n\x05limit\x18\x02 \x01(\x05\"3\n\x11ListTasksResponse\x12\x1e\n\x05tasks\x18\x01 \x03(\x0b\x32\x0f.coder.TaskInfo\",\n\nLLMMessage\x12\r\n\x05round\x18\x01 \x01(\x05\x12\x0f\n\x07\x63ontent\x18\x02 \x01(\t\"=\n\rFileExtracted\x12\x0c\n\x04path\x18\x01 \x01(\t\x12\x10\n\x08language\x18\x02 \x01(\t\x12\x0c\n\x04size\x18\x03 \x01(\x03\":\n\x0c\x46ileRejected\x12\x0c\n\x04path\x18\x01 \x01(\t\x12\x0e\n\x06reason\x18\x02 \x01(\t\x12\x0c\n\x04hunk\x18\x03 \x01(\t\"0\n\x0e\x43ommandStarted\x12\r\n\x05index\x18\x01 \x01(\x05\x12\x0f\n\x07\x63ommand\x18\x02 \x01(\t\"O\n\x0bOutputChunk\x12\r\n\x05index\x18\x01 \x01(\x05\x12#\n\x06stream\x18\x02 \x01(\x0e\x32\x13.coder.OutputStream\x12\x0c\n\x04\x64\x61ta\x18\x03 \x01(\t\"B\n\rCommandExited\x12\r\n\x05index\x18\x01 \x01(\x05\x12\x10\n\x08\x65xitCode\x18\x02 \x01(\x05\x12\x10\n\x08timedOut\x18\x03 \x01(\x08\":\n\x05Retry\x12\r\n\x05round\x18\x01 \x01(\x05\x12\x10\n\x08maxRetry\x18\x02 \x01(\x05\x12\x10\n\x08\x65xitCode\x18\x03 \x01(\x05\"*\n\x08\x46\x65\x65\x64\x62\x61\x63k\x12\r\n\x05round\x18\x01 \x01(\x05\x12\x0f\n\x07\x63ontent\x18\x02 \x01(\t\">\n\x0fWorkspaceSeeded\x12\x0e\n\x06source\x18\x01 \x01(\t\x12\r\n\x05\x66iles\x18\x02 \x01(\x05\x12\x0c\n\x04size\x18\x03 \x01(\x03\"P\n\rImageProgress\x12\r\n\x05image\x18\x01 \x01(\t\x12\"\n\x06\x61\x63tion\x18\x02 \x01(\x0e\x32\x12.coder.ImageAction\x12\x0c\n\x04line\x18\x03 \x01(\t\"F\n\x08ToolCall\x12\r\n\x05round\x18\x01 \x01(\x05\x12\n\n\x02id\x18\x02 \x01(\t\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x11\n\targuments\x18\x04 \x01(\t\"V\n\nToolResult\x12\r\n\x05round\x18\x01 \x01(\x05\x12\n\n\x02id\x18\x02 \x01(\t\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x0f\n\x07\x63ontent\x18\x04 \x01(\t\x12\x0e\n\x06\x66\x61iled\x18\x05 \x01(\x08\",\n\nTerminated\x12\x0e\n\x06reason\x18\x01 \x01(\t\x12\x0e\n\x06result\x18\x02 \x01(\t\"&\n\x05\x45rror\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x0f\n\x07message\x18\x02 \x01(\t\"\x89\x02\n\x07Summary\x12\x0e\n\x06rounds\x18\x01 \x01(\x05\x12\x11\n\tllmTokens\x18\x02 \x01(\x03\x12\x11\n\ttimeTaken\x18\x03 \x01(\x03\x12 \n\x05usage\x18\x04 \x01(\x0b\x32\x11.coder.TokenUsage\x12%\n\nroundUsage\x18\x05 \x03(\x0b\x32\x11.coder.TokenUsage\x12\x1f\n\x07outcome\x18\x06 \x01(\x0e\x32\x0e.coder.Outcome\x12\x10\n\x08\x65xitCode\x18\x07 \x01(\x05\x12\r\n\x05\x66iles\x18\x08 \x03(\t\x12\x16\n\x0ewallTimeMillis\x18\t \x01(\x03\x12%\n\x07network\x18\n \x01(\x0e\x32\x14.coder.NetworkPolicy\"y\n\nTokenUsage\x12\x14\n\x0cpromptTokens\x18\x01 \x01(\x03\x12\x18\n\x10\x63ompletionTokens\x18\x02 \x01(\x03\x12\x13\n\x0btotalTokens\x18\x03 \x01(\x03\x12\x15\n\restimatedCost\x18\x04 \x01(\x01\x12\x0f\n\x07\x63ounted\x18\x05 \x01(\x08\"?\n\rWorkspaceFile\x12\x0c\n\x04path\x18\x01 \x01(\t\x12\x0c\n\x04size\x18\x02 \x01(\x03\x12\x12\n\nmodifiedAt\x18\x03 \x01(\x03\"G\n\x10WorkspaceListing\x12\x0e\n\x06taskId\x18\x01 \x01(\x03\x12#\n\x05\x66iles\x18\x02 \x03(\x0b\x32\x14.coder.WorkspaceFile\"+\n\x0b\x46ileRequest\x12\x0e\n\x06taskId\x18\x01 \x01(\x03\x12\x0c\n\x04path\x18\x02 \x01(\t\"F\n\x0e\x41rchiveRequest\x12\x0e\n\x06taskId\x18\x01 \x01(\x03\x12$\n\x06\x66ormat\x18\x02 \x01(\x0e\x32\x14.coder.ArchiveFormat\"\'\n\tFileChunk\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\x0c\n\x04\x64\x61ta\x18\x02 \x01(\x0c*=\n\tAgentMode\x12\x1a\n\x16\x41GENT_MODE_CODE_BLOCKS\x10\x00\x12\x14\n\x10\x41GENT_MODE_TOOLS\x10\x01*]\n\rNetworkPolicy\x12\x11\n\rNETWORK_UNSET\x10\x00\x12\x10\n\x0cNETWORK_NONE\x10\x01\x12\x15\n\x11NETWORK_ALLOWLIST\x10\x02\x12\x10\n\x0cNETWORK_FULL\x10\x03*O\n\nTaskStatus\x12\n\n\x06QUEUED\x10\x00\x12\x0b\n\x07RUNNING\x10\x01\x12\r\n\tCOMPLETED\x10\x02\x12\r\n\tCANCELLED\x10\x03\x12\n\n\x06\x46\x41ILED\x10\x04*&\n\x0cOutputStream\x12\n\n\x06STDOUT\x10\x00\x12\n\n\x06STDERR\x10\x01*.\n\x0bImageAction\x12\x0e\n\nIMAGE_PULL\x10\x00\x12\x0f\n\x0bIMAGE_BUILD\x10\x01*\x90\x01\n\x07Outcome\x12\x13\n\x0fOUTCOME_UNKNOWN\x10\x00\x12\x16\n\x12OUTCOME_TERMINATED\x10\x01\x12\x17\n\x13OUTCOME_MAX_RETRIES\x10\x02\x12\x15\n\x11OUTCOME_CANCELLED\x10\x03\x12\x11\n\rOUTCOME_ERROR\x10\x04\x12\x15\n\x11OUTCOME_TIMED_OUT\x10\x05*$\n\rArchiveFormat\x12\n\n\x06TAR_GZ\x10\x00\x12\x07\n\x03ZIP\x10\x01\x32\x85\x04\n\x0c\x43oderService\x12\x38\n\x0b\x45xecuteCode\x12\x12.coder.CodeRequest\x1a\x13.coder.CodeResponse0\x01\x12\x31\n\nSubmitTask\x12\x12.coder.CodeRequest\x1a\x0f.coder.TaskInfo\x12,\n\x07GetTask\x12\x10.coder.TaskQuery\x1a\x0f.coder.TaskInfo\x12\x35\n\nAttachTask\x12\x10.coder.TaskQuery\x1a\x13.coder.CodeResponse0\x01\x12/\n\nCancelTask\x12\x10.coder.TaskQuery\x1a\x0f.coder.TaskInfo\x12>\n\tListTasks\x12\x17.coder.ListTasksRequest\x1a\x18.coder.ListTasksResponse\x12:\n\rListWorkspace\x12\x10.coder.TaskQuery\x1a\x17.coder.WorkspaceListing\x12\x36\n\x0c\x44ownloadFile\x12\x12.coder.FileRequest\x1a\x10.coder.FileChunk0\x01\x12>\n\x11\x44ownloadWorkspace\x12\x15.coder.ArchiveRequest\x1a\x10.coder.FileChunk0\x01\x42\rZ\x0b./protos/gob\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -32,10 +32,78 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'coder_pb2', _globals)
 if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._loaded_options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z\013./protos/go'
+  _globals['_AGENTMODE']._serialized_start=3233
+  _globals['_AGENTMODE']._serialized_end=3294
+  _globals['_NETWORKPOLICY']._serialized_start=3296
+  _globals['_NETWORKPOLICY']._serialized_end=3389
+  _globals['_TASKSTATUS']._serialized_start=3391
+  _globals['_TASKSTATUS']._serialized_end=3470
+  _globals['_OUTPUTSTREAM']._serialized_start=3472
+  _globals['_OUTPUTSTREAM']._serialized_end=3510
+  _globals['_IMAGEACTION']._serialized_start=3512
+  _globals['_IMAGEACTION']._serialized_end=3558
+  _globals['_OUTCOME']._serialized_start=3561
+  _globals['_OUTCOME']._serialized_end=3705
+  _globals['_ARCHIVEFORMAT']._serialized_start=3707
+  _globals['_ARCHIVEFORMAT']._serialized_end=3743
   _globals['_CODEREQUEST']._serialized_start=23
-  _globals['_CODEREQUEST']._serialized_end=161
-  _globals['_CODERESPONSE']._serialized_start=163
-  _globals['_CODERESPONSE']._serialized_end=191
-  _globals['_CODERSERVICE']._serialized_start=193
-  _globals['_CODERSERVICE']._serialized_end=265
+  _globals['_CODEREQUEST']._serialized_end=308
+  _globals['_LIMITS']._serialized_start=311
+  _globals['_LIMITS']._serialized_end=460
+  _globals['_SEED']._serialized_start=462
+  _globals['_SEED']._serialized_end=583
+  _globals['_CODERESPONSE']._serialized_start=586
+  _globals['_CODERESPONSE']._serialized_end=1296
+  _globals['_TASKQUERY']._serialized_start=1298
+  _globals['_TASKQUERY']._serialized_end=1325
+  _globals['_TASKINFO']._serialized_start=1328
+  _globals['_TASKINFO']._serialized_end=1557
+  _globals['_LISTTASKSREQUEST']._serialized_start=1559
+  _globals['_LISTTASKSREQUEST']._serialized_end=1627
+  _globals['_LISTTASKSRESPONSE']._serialized_start=1629
+  _globals['_LISTTASKSRESPONSE']._serialized_end=1680
+  _globals['_LLMMESSAGE']._serialized_start=1682
+  _globals['_LLMMESSAGE']._serialized_end=1726
+  _globals['_FILEEXTRACTED']._serialized_start=1728
+  _globals['_FILEEXTRACTED']._serialized_end=1789
+  _globals['_FILEREJECTED']._serialized_start=1791
+  _globals['_FILEREJECTED']._serialized_end=1849
+  _globals['_COMMANDSTARTED']._serialized_start=1851
+  _globals['_COMMANDSTARTED']._serialized_end=1899
+  _globals['_OUTPUTCHUNK']._serialized_start=1901
+  _globals['_OUTPUTCHUNK']._serialized_end=1980
+  _globals['_COMMANDEXITED']._serialized_start=1982
+  _globals['_COMMANDEXITED']._serialized_end=2048
+  _globals['_RETRY']._serialized_start=2050
+  _globals['_RETRY']._serialized_end=2108
+  _globals['_FEEDBACK']._serialized_start=2110
+  _globals['_FEEDBACK']._serialized_end=2152
+  _globals['_WORKSPACESEEDED']._serialized_start=2154
+  _globals['_WORKSPACESEEDED']._serialized_end=2216
+  _globals['_IMAGEPROGRESS']._serialized_start=2218
+  _globals['_IMAGEPROGRESS']._serialized_end=2298
+  _globals['_TOOLCALL']._serialized_start=2300
+  _globals['_TOOLCALL']._serialized_end=2370
+  _globals['_TOOLRESULT']._serialized_start=2372
+  _globals['_TOOLRESULT']._serialized_end=2458
+  _globals['_TERMINATED']._serialized_start=2460
+  _globals['_TERMINATED']._serialized_end=2504
+  _globals['_ERROR']._serialized_start=2506
+  _globals['_ERROR']._serialized_end=2544
+  _globals['_SUMMARY']._serialized_start=2547
+  _globals['_SUMMARY']._serialized_end=2812
+  _globals['_TOKENUSAGE']._serialized_start=2814
+  _globals['_TOKENUSAGE']._serialized_end=2935
+  _globals['_WORKSPACEFILE']._serialized_start=2937
+  _globals['_WORKSPACEFILE']._serialized_end=3000
+  _globals['_WORKSPACELISTING']._serialized_start=3002
+  _globals['_WORKSPACELISTING']._serialized_end=3073
+  _globals['_FILEREQUEST']._serialized_start=3075
+  _globals['_FILEREQUEST']._serialized_end=3118
+  _globals['_ARCHIVEREQUEST']._serialized_start=3120
+  _globals['_ARCHIVEREQUEST']._serialized_end=3190
+  _globals['_FILECHUNK']._serialized_start=3192
+  _globals['_FILECHUNK']._serialized_end=3231
+  _globals['_CODERSERVICE']._serialized_start=3746
+  _globals['_CODERSERVICE']._serialized_end=4263
 # @@protoc_insertion_point(module_scope)
@@ -39,6 +39,46 @@ class CoderServiceStub(object):
                 request_serializer=coder__pb2.CodeRequest.SerializeToString,
                 response_deserializer=coder__pb2.CodeResponse.FromString,
                 _registered_method=True)
+        self.SubmitTask = channel.unary_unary(
+                '/coder.CoderService/SubmitTask',
+                request_serializer=coder__pb2.CodeRequest.SerializeToString,
+                response_deserializer=coder__pb2.TaskInfo.FromString,
+                _registered_method=True)
+        self.GetTask = channel.unary_unary(
+                '/coder.CoderService/GetTask',
+                request_serializer=coder__pb2.TaskQuery.SerializeToString,
+                response_deserializer=coder__pb2.TaskInfo.FromString,
+                _registered_method=True)
+        self.AttachTask = channel.unary_stream(
+                '/coder.CoderService/AttachTask',
+                request_serializer=coder__pb2.TaskQuery.SerializeToString,
+                response_deserializer=coder__pb2.CodeResponse.FromString,
+                _registered_method=True)
+        self.CancelTask = channel.unary_unary(
+                '/coder.CoderService/CancelTask',
+                request_serializer=coder__pb2.TaskQuery.SerializeToString,
+                response_deserializer=coder__pb2.TaskInfo.FromString,
+                _registered_method=True)
+        self.ListTasks = channel.unary_unary(
+                '/coder.CoderService/ListTasks',
+                request_serializer=coder__pb2.ListTasksRequest.SerializeToString,
+                response_deserializer=coder__pb2.ListTasksResponse.FromString,
+                _registered_method=True)
+        self.ListWorkspace = channel.unary_unary(
+                '/coder.CoderService/ListWorkspace',
+                request_serializer=coder__pb2.TaskQuery.SerializeToString,
+                response_deserializer=coder__pb2.WorkspaceListing.FromString,
+                _registered_method=True)
+        self.DownloadFile = channel.unary_stream(
+                '/coder.CoderService/DownloadFile',
+                request_serializer=coder__pb2.FileRequest.SerializeToString,
+                response_deserializer=coder__pb2.FileChunk.FromString,
+                _registered_method=True)
+        self.DownloadWorkspace = channel.unary_stream(
+                '/coder.CoderService/DownloadWorkspace',
+                request_serializer=coder__pb2.ArchiveRequest.SerializeToString,
+                response_deserializer=coder__pb2.FileChunk.FromString,
+                _registered_method=True)
 
 
 class CoderServiceServicer(object):
@@ -50,6 +90,54 @@ class CoderServiceServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def SubmitTask(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetTask(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AttachTask(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CancelTask(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListTasks(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListWorkspace(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DownloadFile(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DownloadWorkspace(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_CoderServiceServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -58,6 +146,46 @@ def add_CoderServiceServicer_to_server(servicer, server):
                     request_deserializer=coder__pb2.CodeRequest.FromString,
                     response_serializer=coder__pb2.CodeResponse.SerializeToString,
             ),
+            'SubmitTask': grpc.unary_unary_rpc_method_handler(
+                    servicer.SubmitTask,
+                    request_deserializer=coder__pb2.CodeRequest.FromString,
+                    response_serializer=coder__pb2.TaskInfo.SerializeToString,
+            ),
+            'GetTask': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetTask,
+                    request_deserializer=coder__pb2.TaskQuery.FromString,
+                    response_serializer=coder__pb2.TaskInfo.SerializeToString,
+            ),
+            'AttachTask': grpc.unary_stream_rpc_method_handler(
+                    servicer.AttachTask,
+                    request_deserializer=coder__pb2.TaskQuery.FromString,
+                    response_serializer=coder__pb2.CodeResponse.SerializeToString,
+            ),
+            'CancelTask': grpc.unary_unary_rpc_method_handler(
+                    servicer.CancelTask,
+                    request_deserializer=coder__pb2.TaskQuery.FromString,
+                    response_serializer=coder__pb2.TaskInfo.SerializeToString,
+            ),
+            'ListTasks': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListTasks,
+                    request_deserializer=coder__pb2.ListTasksRequest.FromString,
+                    response_serializer=coder__pb2.ListTasksResponse.SerializeToString,
+            ),
+            'ListWorkspace': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListWorkspace,
+                    request_deserializer=coder__pb2.TaskQuery.FromString,
+                    response_serializer=coder__pb2.WorkspaceListing.SerializeToString,
+            ),
+            'DownloadFile': grpc.unary_stream_rpc_method_handler(
+                    servicer.DownloadFile,
+                    request_deserializer=coder__pb2.FileRequest.FromString,
+                    response_serializer=coder__pb2.FileChunk.SerializeToString,
+            ),
+            'DownloadWorkspace': grpc.unary_stream_rpc_method_handler(
+                    servicer.DownloadWorkspace,
+                    request_deserializer=coder__pb2.ArchiveRequest.FromString,
+                    response_serializer=coder__pb2.FileChunk.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'coder.CoderService', rpc_method_handlers)
@@ -95,3 +223,219 @@ class CoderService(object):
             timeout,
             metadata,
             _registered_method=True)
+
+    @staticmethod
+    def SubmitTask(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/coder.CoderService/SubmitTask',
+            coder__pb2.CodeRequest.SerializeToString,
+            coder__pb2.TaskInfo.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def GetTask(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/coder.CoderService/GetTask',
+            coder__pb2.TaskQuery.SerializeToString,
+            coder__pb2.TaskInfo.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def AttachTask(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/coder.CoderService/AttachTask',
+            coder__pb2.TaskQuery.SerializeToString,
+            coder__pb2.CodeResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def CancelTask(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/coder.CoderService/CancelTask',
+            coder__pb2.TaskQuery.SerializeToString,
+            coder__pb2.TaskInfo.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListTasks(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/coder.CoderService/ListTasks',
+            coder__pb2.ListTasksRequest.SerializeToString,
+            coder__pb2.ListTasksResponse.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def ListWorkspace(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(
+            request,
+            target,
+            '/coder.CoderService/ListWorkspace',
+            coder__pb2.TaskQuery.SerializeToString,
+            coder__pb2.WorkspaceListing.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DownloadFile(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/coder.CoderService/DownloadFile',
+            coder__pb2.FileRequest.SerializeToString,
+            coder__pb2.FileChunk.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
+
+    @staticmethod
+    def DownloadWorkspace(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/coder.CoderService/DownloadWorkspace',
+            coder__pb2.ArchiveRequest.SerializeToString,
+            coder__pb2.FileChunk.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+            _registered_method=True)
@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rservice.proto\x12\x0b\x63omputation\"\x1e\n\rStreamRequest\x12\r\n\x05query\x18\x01 \x01(\t\"\x1e\n\x0eStreamResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\t2X\n\rStreamService\x12G\n\nStreamData\x12\x1a.computation.StreamRequest\x1a\x1b.computation.StreamResponse0\x01\x42\rZ\x0b./protos/gob\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rservice.proto\x12\x0b\x63omputation\"X\n\rStreamRequest\x12\r\n\x05query\x18\x01 \x01(\t\x12\x14\n\x0csystemPrompt\x18\x02 \x01(\t\x12\x10\n\x08LLMModel\x18\x03 \x01(\t\x12\x10\n\x08provider\x18\x04 \x01(\t\"\x1e\n\x0eStreamResponse\x12\x0c\n\x04\x64\x61ta\x18\x01 \x01(\t2X\n\rStreamService\x12G\n\nStreamData\x12\x1a.computation.StreamRequest\x1a\x1b.computation.StreamResponse0\x01\x42\rZ\x0b./protos/gob\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -33,9 +33,9 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._loaded_options = None
   _globals['DESCRIPTOR']._serialized_options = b'Z\013./protos/go'
   _globals['_STREAMREQUEST']._serialized_start=30
-  _globals['_STREAMREQUEST']._serialized_end=60
-  _globals['_STREAMRESPONSE']._serialized_start=62
-  _globals['_STREAMRESPONSE']._serialized_end=92
-  _globals['_STREAMSERVICE']._serialized_start=94
-  _globals['_STREAMSERVICE']._serialized_end=182
+  _globals['_STREAMREQUEST']._serialized_end=118
+  _globals['_STREAMRESPONSE']._serialized_start=120
+  _globals['_STREAMRESPONSE']._serialized_end=150
+  _globals['_STREAMSERVICE']._serialized_start=152
+  _globals['_STREAMSERVICE']._serialized_end=240
 # @@protoc_insertion_point(module_scope)
@@ -1,16 +1,75 @@
 package types
 
 import (
+	pb "codexec/protos/go"
 	"context"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/tmc/langchaingo/llms"
 )
 
+type TokenUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+	// EstimatedCost is in USD, zero when the model has no price in config.toml
+	EstimatedCost float64
+	// Counted is set when the provider reported no usage and tokens were counted locally
+	Counted bool
+}
+
 type InstrumentationStats struct {
-	LLMTokens int
-	TimeTaken int64
+	LLMTokens  int
+	TimeTaken  int64
+	Rounds     int32
+	Usage      TokenUsage
+	RoundUsage []TokenUsage
+	Outcome    pb.Outcome
+	ExitCode   int
+	Files      []string
+	StartedAt  time.Time
+	WallTime   time.Duration
+	Network    pb.NetworkPolicy
+}
+
+// Limits bound the resources of the code run for a task, zero means unlimited.
+type Limits struct {
+	CPUs           float64
+	CPUShares      int64
+	MemoryMB       int64
+	Pids           int64
+	TmpfsMB        int64
+	CommandTimeout time.Duration
+	TaskTimeout    time.Duration
+}
+
+// Buildpack is how code is run in an image, see lib/buildpack.
+type Buildpack struct {
+	Name  string `toml:"-"`
+	Image string `toml:"image"`
+	// Languages are the code block tags it runs, e.g. python and py
+	Languages  []string `toml:"languages"`
+	Extensions []string `toml:"extensions"`
+	// DependencyFiles name the manifests Install reads, e.g. requirements.txt
+	DependencyFiles []string `toml:"dependencyFiles"`
+	// Install runs before the code when one of DependencyFiles is in the workspace
+	Install string `toml:"install"`
+	// Build compiles a source file before Run, {file} stands for its path
+	// and {out} for the program it writes
+	Build string `toml:"build"`
+	// Run runs a source file, {file} stands for its path and {out} for the
+	// program Build wrote
+	Run string `toml:"run"`
+	// Env is KEY=value set for every command run in the containers of the
+	// image, e.g. where its tools keep what they download and build
+	Env []string `toml:"env"`
+}
+
+// EventEmitter publishes typed task events to whoever follows the task.
+type EventEmitter interface {
+	Emit(event *pb.CodeResponse)
 }
 
 type CoderAgent struct {
@@ -19,27 +78,50 @@ type CoderAgent struct {
 	DockerImage         string
 	DockerContainerName string
 	MaxRetry            int32
+	LLMProvider         string
 	LLMModel            string
 	WorkingDirectory    string
-	MaxTimeOut          int32
+	Limits              Limits
+	Network             pb.NetworkPolicy
+	Buildpack           *Buildpack
 	Conversation        []llms.MessageContent
 	Logger              *log.Logger
+	Events              EventEmitter
 	Instrumentation     InstrumentationStats
 	Context             context.Context
 	Cancel              context.CancelFunc
 	Task                *Task
 }
 
+type TaskKind int
+
+const (
+	// TaskKindCode runs the full write, execute and retry agent loop.
+	TaskKindCode TaskKind = iota
+	// TaskKindChat streams a single LLM answer without executing code.
+	TaskKindChat
+	// TaskKindTools lets the model drive the task through tool calls.
+	TaskKindTools
+)
+
 type Task struct {
 	Id               int
+	Kind             TaskKind
+	ContainerName    string
 	SystemPrompt     string
 	UserPrompt       string
 	WorkingDirectory string
 	DockerImage      string
 	MaxRetry         int32
+	LLMProvider      string
 	LLMModel         string
+	Seed             *pb.Seed
+	Limits           Limits
+	Network          pb.NetworkPolicy
+	Buildpack        *Buildpack
 	CompleteSignal   chan<- bool
 	Logger           *log.Logger
+	Events           EventEmitter
 	Context          context.Context
 	Cancel           context.CancelFunc
 }